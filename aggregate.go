@@ -0,0 +1,79 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"github.com/spf13/cast"
+)
+
+// numbers returns the numeric values addressed by key, skipping the rest.
+func (entity *Entity) numbers(key string) []float64 {
+	var s []float64
+	for _, v := range entity.collect(key) {
+		if f, err := cast.ToFloat64E(v); err == nil {
+			s = append(s, f)
+		}
+	}
+	return s
+}
+
+// SumFloat64 returns the sum of the numeric values addressed by key,
+// e.g. e.SumFloat64("items:*:price").
+func (entity *Entity) SumFloat64(key string) float64 {
+	var sum float64
+	for _, f := range entity.numbers(key) {
+		sum += f
+	}
+	return sum
+}
+
+// Min returns the smallest numeric value addressed by key, or 0 if there is none.
+func (entity *Entity) Min(key string) float64 {
+	s := entity.numbers(key)
+	if len(s) == 0 {
+		return 0
+	}
+	min := s[0]
+	for _, f := range s[1:] {
+		if f < min {
+			min = f
+		}
+	}
+	return min
+}
+
+// Max returns the largest numeric value addressed by key, or 0 if there is none.
+func (entity *Entity) Max(key string) float64 {
+	s := entity.numbers(key)
+	if len(s) == 0 {
+		return 0
+	}
+	max := s[0]
+	for _, f := range s[1:] {
+		if f > max {
+			max = f
+		}
+	}
+	return max
+}
+
+// Avg returns the mean of the numeric values addressed by key, or 0 if there is none.
+func (entity *Entity) Avg(key string) float64 {
+	s := entity.numbers(key)
+	if len(s) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, f := range s {
+		sum += f
+	}
+	return sum / float64(len(s))
+}
+
+// Count returns the number of values addressed by key.
+func (entity *Entity) Count(key string) int {
+	return len(entity.collect(key))
+}
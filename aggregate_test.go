@@ -0,0 +1,38 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"testing"
+)
+
+func TestEntity_Aggregate(t *testing.T) {
+	e := NewByJSON([]byte(`{"items": [
+		{"price": 10, "qty": 1},
+		{"price": 2.5, "qty": 4},
+		{"price": "7.5"},
+		{"name": "free"}
+	]}`))
+
+	if sum := e.SumFloat64("items:*:price"); sum != 20 {
+		t.Errorf("SumFloat64 is %v, not 20", sum)
+	}
+	if min := e.Min("items:*:price"); min != 2.5 {
+		t.Errorf("Min is %v, not 2.5", min)
+	}
+	if max := e.Max("items:*:price"); max != 10 {
+		t.Errorf("Max is %v, not 10", max)
+	}
+	if avg := e.Avg("items:*:qty"); avg != 2.5 {
+		t.Errorf("Avg is %v, not 2.5", avg)
+	}
+	if n := e.Count("items:*:price"); n != 3 {
+		t.Errorf("Count is %d, not 3", n)
+	}
+	if n := e.Count("items:1:price"); n != 1 {
+		t.Errorf("Count of indexed path is %d, not 1", n)
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Change is the value of a key before and after it was changed.
+// A nil Old or New means the key was missing.
+type Change struct {
+	Old interface{}
+	New interface{}
+}
+
+// tracked is a changed key recorded by track.
+type tracked struct {
+	path []string
+	old  interface{}
+}
+
+// WithChangeTracking makes the Entity record the keys changed by Set, Delete,
+// Merge and the other mutating methods, see Dirty and Changes.
+func WithChangeTracking() Option {
+	return func(entity *Entity) {
+		entity.changes = make(map[string]tracked)
+	}
+}
+
+// track records the value at the normalized path, joined as key, before its
+// first change.
+func (entity *Entity) track(key string, path []string) {
+	if entity.changes == nil {
+		return
+	}
+	if _, ok := entity.changes[key]; ok {
+		return
+	}
+	old, _ := entity.lookupPath(path)
+	entity.changes[key] = tracked{path: path, old: deepCopy(old)}
+}
+
+// Dirty returns the sorted keys changed since change tracking started or
+// ResetDirty was called.
+func (entity *Entity) Dirty() []string {
+	entity.evictExpired()
+	entity.mu.RLock()
+	defer entity.mu.RUnlock()
+
+	keys := make([]string, 0, len(entity.changes))
+	for k := range entity.changes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Changes returns the changed keys with their value before the first change
+// and their current value.
+func (entity *Entity) Changes() map[string]Change {
+	entity.evictExpired()
+	entity.mu.RLock()
+	defer entity.mu.RUnlock()
+
+	changes := make(map[string]Change, len(entity.changes))
+	for k, t := range entity.changes {
+		cur, _ := entity.lookupPath(t.path)
+		changes[k] = Change{Old: t.old, New: deepCopy(cur)}
+	}
+	return changes
+}
+
+// ResetDirty forgets the changes recorded so far.
+func (entity *Entity) ResetDirty() {
+	entity.mu.Lock()
+	defer entity.mu.Unlock()
+
+	if entity.changes != nil {
+		entity.changes = make(map[string]tracked)
+	}
+}
+
+// Diff returns the patch turning a into b, in the form of a JSON merge patch
+// (RFC 7386): objects are compared key by key, other values that differ are
+// taken from b, and keys missing from b are set to nil.
+// Applying it to a with ApplyMergePatch yields the data of b.
+// The returned Entity shares the settings of a.
+func Diff(a, b *Entity) *Entity {
+	patch := diffMaps(a.snapshot(), b.snapshot())
+	if patch == nil {
+		patch = make(map[string]interface{})
+	}
+	return a.child(patch)
+}
+
+// diffMaps returns the merge patch turning a into b, or nil if they are equal.
+func diffMaps(a, b map[string]interface{}) map[string]interface{} {
+	var patch map[string]interface{}
+	add := func(k string, v interface{}) {
+		if patch == nil {
+			patch = make(map[string]interface{})
+		}
+		patch[k] = v
+	}
+
+	for k, bv := range b {
+		av, ok := a[k]
+		if !ok {
+			add(k, bv)
+			continue
+		}
+		am, aok := av.(map[string]interface{})
+		bm, bok := bv.(map[string]interface{})
+		if aok && bok {
+			if p := diffMaps(am, bm); p != nil {
+				add(k, p)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(av, bv) {
+			add(k, bv)
+		}
+	}
+	for k := range a {
+		if _, ok := b[k]; !ok {
+			add(k, nil)
+		}
+	}
+	return patch
+}
@@ -0,0 +1,68 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWithChangeTracking(t *testing.T) {
+	e := New(map[string]interface{}{
+		"user": map[string]interface{}{"name": "jack", "age": 18},
+		"tags": []interface{}{"a"},
+	}, WithChangeTracking())
+
+	e.Set("user:name", "rose")
+	e.Set("user:name", "lily")
+	e.Set("user:email", "lily@example.com")
+	e.Delete("tags")
+	e.MergeMap(map[string]interface{}{"user": map[string]interface{}{"age": 19}})
+
+	if dirty := e.Dirty(); !reflect.DeepEqual(dirty, []string{"tags", "user:age", "user:email", "user:name"}) {
+		t.Errorf("Dirty is %v", dirty)
+	}
+	want := map[string]Change{
+		"tags":       {Old: []interface{}{"a"}},
+		"user:age":   {Old: 18, New: 19},
+		"user:email": {New: "lily@example.com"},
+		"user:name":  {Old: "jack", New: "lily"},
+	}
+	if changes := e.Changes(); !reflect.DeepEqual(changes, want) {
+		t.Errorf("Changes is %v", changes)
+	}
+
+	e.ResetDirty()
+	if len(e.Dirty()) != 0 || len(e.Changes()) != 0 {
+		t.Error("ResetDirty should forget the changes")
+	}
+	if New(nil).Set("a", 1).Dirty() == nil {
+		t.Error("Dirty without change tracking should be empty, not nil")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := NewByJSON([]byte(`{"name": "jack", "db": {"host": "localhost", "port": 5432}, "tags": ["a"], "old": true}`))
+	b := NewByJSON([]byte(`{"name": "jack", "db": {"host": "db.prod", "port": 5432}, "tags": ["a", "b"], "new": 1}`))
+
+	want := map[string]interface{}{
+		"db":   map[string]interface{}{"host": "db.prod"},
+		"tags": []interface{}{"a", "b"},
+		"old":  nil,
+		"new":  float64(1),
+	}
+	patch := Diff(a, b)
+	if !reflect.DeepEqual(patch.GetData(), want) {
+		t.Errorf("Diff is %v", patch.GetData())
+	}
+
+	if err := a.ApplyMergePatch(patch.GetData()); err != nil {
+		t.Fatal("ApplyMergePatch error:", err)
+	}
+	if len(Diff(a, b).GetData()) != 0 {
+		t.Errorf("applying the patch should leave no difference, got %v", Diff(a, b).GetData())
+	}
+}
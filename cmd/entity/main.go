@@ -0,0 +1,219 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// Command entity queries and edits documents using entity key paths.
+//
+// Usage:
+//
+//	entity get [-f format] file key
+//	entity set [-f format] [-w] file key value
+//	entity convert [-f format] -to format file
+//	entity merge [-f format] [-to format] file overlay...
+//	entity diff [-f format] [-to format] file other
+//
+// A file of "-" reads the document from stdin. The format defaults to the
+// file extension, or json. merge deep merges each overlay into file in order
+// and writes the result in the format of file. diff writes the JSON merge
+// patch (RFC 7386) turning file into other.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lyf-coder/entity"
+)
+
+const usage = `usage:
+	entity get [-f format] file key
+	entity set [-f format] [-w] file key value
+	entity convert [-f format] -to format file
+	entity merge [-f format] [-to format] file overlay...
+	entity diff [-f format] [-to format] file other
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "entity:", err)
+		os.Exit(1)
+	}
+}
+
+// errUsage reports a malformed command line.
+var errUsage = errors.New(usage)
+
+// run executes the command line args.
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	format := fs.String("f", "", "input format")
+	to := fs.String("to", "", "output format")
+	write := fs.Bool("w", false, "write the result to the file instead of stdout")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	rest := fs.Args()
+
+	switch args[0] {
+	case "get":
+		if len(rest) != 2 {
+			return errUsage
+		}
+		e, _, err := load(rest[0], *format, stdin)
+		if err != nil {
+			return err
+		}
+		return printValue(stdout, e.Get(rest[1]))
+	case "set":
+		if len(rest) != 3 {
+			return errUsage
+		}
+		e, f, err := load(rest[0], *format, stdin)
+		if err != nil {
+			return err
+		}
+		if err := e.SetE(rest[1], parseValue(rest[2])); err != nil {
+			return err
+		}
+		if *write && rest[0] != "-" {
+			return save(rest[0], f, e)
+		}
+		return encode(stdout, f, e)
+	case "convert":
+		if len(rest) != 1 || *to == "" {
+			return errUsage
+		}
+		e, _, err := load(rest[0], *format, stdin)
+		if err != nil {
+			return err
+		}
+		return encode(stdout, *to, e)
+	case "merge":
+		if len(rest) < 2 {
+			return errUsage
+		}
+		e, f, err := load(rest[0], *format, stdin)
+		if err != nil {
+			return err
+		}
+		for _, file := range rest[1:] {
+			overlay, _, err := load(file, "", stdin)
+			if err != nil {
+				return err
+			}
+			if err := e.Merge(overlay); err != nil {
+				return err
+			}
+		}
+		if *to != "" {
+			f = *to
+		}
+		return encode(stdout, f, e)
+	case "diff":
+		if len(rest) != 2 {
+			return errUsage
+		}
+		a, f, err := load(rest[0], *format, stdin)
+		if err != nil {
+			return err
+		}
+		b, _, err := load(rest[1], "", stdin)
+		if err != nil {
+			return err
+		}
+		if *to != "" {
+			f = *to
+		}
+		return encode(stdout, f, entity.Diff(a, b))
+	default:
+		return errUsage
+	}
+}
+
+// formatOf returns format, or the format implied by the extension of file.
+func formatOf(file, format string) string {
+	if format != "" {
+		return format
+	}
+	if ext := strings.TrimPrefix(filepath.Ext(file), "."); ext != "" {
+		return ext
+	}
+	return "json"
+}
+
+// load reads the document in file, or stdin for "-", and its format.
+func load(file, format string, stdin io.Reader) (*entity.Entity, string, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if file == "-" {
+		data, err = ioutil.ReadAll(stdin)
+	} else {
+		data, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	format = formatOf(file, format)
+	e, err := entity.NewByFormat(format, data)
+	return e, format, err
+}
+
+// save writes e to file in format.
+func save(file, format string, e *entity.Entity) error {
+	b, err := e.Encode(format)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, b, 0644)
+}
+
+// encode writes e to w in format.
+func encode(w io.Writer, format string, e *entity.Entity) error {
+	b, err := e.Encode(format)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", b)
+	return err
+}
+
+// printValue writes scalars as they are and anything else as JSON.
+func printValue(w io.Writer, v interface{}) error {
+	switch v.(type) {
+	case nil:
+		return errors.New("key not found")
+	case map[string]interface{}, map[interface{}]interface{}, []interface{}, []map[string]interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		v = string(b)
+	}
+	_, err := fmt.Fprintln(w, v)
+	return err
+}
+
+// parseValue parses s as JSON, falling back to the plain string.
+func parseValue(s string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return s
+	}
+	return v
+}
@@ -0,0 +1,82 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	for _, tt := range []struct {
+		args  []string
+		stdin string
+		want  string
+	}{
+		{[]string{"get", "../../test_data.json", "event:header:name"}, "", "TextInput\n"},
+		{[]string{"get", "-", "a"}, `{"a": {"b": [1, 2]}}`, `{"b":[1,2]}` + "\n"},
+		{[]string{"set", "-", "a:c", "true"}, `{"a": {"b": 1}}`, `{"a":{"b":1,"c":true}}` + "\n"},
+		{[]string{"set", "-", "name", "jack"}, `{}`, `{"name":"jack"}` + "\n"},
+		{[]string{"convert", "-to", "json", "-"}, `{ "a" : 1 }`, `{"a":1}` + "\n"},
+	} {
+		var out bytes.Buffer
+		if err := run(tt.args, strings.NewReader(tt.stdin), &out); err != nil {
+			t.Errorf("run %v error: %v", tt.args, err)
+			continue
+		}
+		if out.String() != tt.want {
+			t.Errorf("run %v output is %q, not %q", tt.args, out.String(), tt.want)
+		}
+	}
+}
+
+func TestRun_MergeDiff(t *testing.T) {
+	dir, err := ioutil.TempDir("", "entity")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	overlay := filepath.Join(dir, "overlay.yaml")
+	if err := ioutil.WriteFile(overlay, []byte("a:\n  c: 2\nd: [x]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := run([]string{"merge", "-", overlay}, strings.NewReader(`{"a": {"b": 1}, "d": [1, 2]}`), &out); err != nil {
+		t.Fatal("merge error:", err)
+	}
+	if want := `{"a":{"b":1,"c":2},"d":["x"]}` + "\n"; out.String() != want {
+		t.Errorf("merge output is %q, not %q", out.String(), want)
+	}
+
+	out.Reset()
+	if err := run([]string{"diff", "-", overlay}, strings.NewReader(`{"a": {"b": 1}, "d": ["x"]}`), &out); err != nil {
+		t.Fatal("diff error:", err)
+	}
+	if want := `{"a":{"b":null,"c":2}}` + "\n"; out.String() != want {
+		t.Errorf("diff output is %q, not %q", out.String(), want)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"get", "-"},
+		{"get", "-", "missing"},
+		{"convert", "-"},
+		{"merge", "-"},
+		{"diff", "-"},
+		{"unknown"},
+	} {
+		if err := run(args, strings.NewReader(`{}`), &bytes.Buffer{}); err == nil {
+			t.Errorf("run %v should fail", args)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Config controls the generated code.
+type Config struct {
+	// Package is the package name of the generated file.
+	Package string
+	// Type is the name of the root wrapper type.
+	Type string
+	// Delim is the key delimiter of the wrapped entity.
+	Delim string
+}
+
+// node describes the shape of a value.
+type node struct {
+	kind   string // "object", "array" or a scalar kind: "string", "int", "float", "bool", "any"
+	fields map[string]*node
+	elem   *node
+}
+
+// Generate returns the Go source of typed accessors for the sample JSON
+// document or JSON Schema in data.
+func Generate(data []byte, cfg Config) ([]byte, error) {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	var root *node
+	if m, ok := doc.(map[string]interface{}); ok && isSchema(m) {
+		root = fromSchema(m)
+	} else {
+		root = fromSample(doc)
+	}
+	if root.kind != "object" {
+		return nil, fmt.Errorf("root value must be an object, got %s", root.kind)
+	}
+
+	g := &generator{cfg: cfg, types: map[string]bool{cfg.Type: true}}
+	g.printf("// Code generated by entitygen. DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", cfg.Package)
+	g.printf("import \"github.com/lyf-coder/entity\"\n\n")
+	g.printf("// %s provides typed access to an entity.Entity.\n", cfg.Type)
+	g.printf("type %s struct {\n\te *entity.Entity\n\tprefix string\n}\n\n", cfg.Type)
+	g.printf("// New%s wraps e.\n", cfg.Type)
+	g.printf("func New%s(e *entity.Entity) *%s {\n\treturn &%s{e: e}\n}\n\n", cfg.Type, cfg.Type, cfg.Type)
+	g.printf("// Entity returns the wrapped entity.Entity.\n")
+	g.printf("func (x *%s) Entity() *entity.Entity {\n\treturn x.e\n}\n", cfg.Type)
+	g.object(cfg.Type, root, true)
+
+	return format.Source(g.buf.Bytes())
+}
+
+// isSchema reports whether m looks like a JSON Schema rather than a sample.
+func isSchema(m map[string]interface{}) bool {
+	if _, ok := m["$schema"]; ok {
+		return true
+	}
+	_, ok := m["properties"].(map[string]interface{})
+	return ok && m["type"] == "object"
+}
+
+// fromSample infers the shape of a sample value.
+func fromSample(v interface{}) *node {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		n := &node{kind: "object", fields: make(map[string]*node)}
+		for k, val := range v {
+			n.fields[k] = fromSample(val)
+		}
+		return n
+	case []interface{}:
+		n := &node{kind: "array", elem: &node{kind: "any"}}
+		if len(v) > 0 {
+			n.elem = fromSample(v[0])
+		}
+		return n
+	case string:
+		return &node{kind: "string"}
+	case bool:
+		return &node{kind: "bool"}
+	case float64:
+		if v == math.Trunc(v) {
+			return &node{kind: "int"}
+		}
+		return &node{kind: "float"}
+	default:
+		return &node{kind: "any"}
+	}
+}
+
+// fromSchema reads the shape described by a JSON Schema.
+func fromSchema(s map[string]interface{}) *node {
+	switch s["type"] {
+	case "object":
+		n := &node{kind: "object", fields: make(map[string]*node)}
+		props, _ := s["properties"].(map[string]interface{})
+		for k, p := range props {
+			ps, _ := p.(map[string]interface{})
+			n.fields[k] = fromSchema(ps)
+		}
+		return n
+	case "array":
+		items, _ := s["items"].(map[string]interface{})
+		return &node{kind: "array", elem: fromSchema(items)}
+	case "string":
+		return &node{kind: "string"}
+	case "integer":
+		return &node{kind: "int"}
+	case "number":
+		return &node{kind: "float"}
+	case "boolean":
+		return &node{kind: "bool"}
+	default:
+		return &node{kind: "any"}
+	}
+}
+
+type generator struct {
+	cfg Config
+	buf bytes.Buffer
+	// names of the types declared so far
+	types map[string]bool
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// scalar returns the Go type and Entity getter of a scalar kind.
+func scalar(kind string) (goType, getter string) {
+	switch kind {
+	case "string":
+		return "string", "GetString"
+	case "int":
+		return "int", "GetInt"
+	case "float":
+		return "float64", "GetFloat64"
+	case "bool":
+		return "bool", "GetBool"
+	default:
+		return "interface{}", "Get"
+	}
+}
+
+// object emits the key helper and the accessors of the object type name.
+// root is set for the root type, which also has an Entity method.
+func (g *generator) object(name string, n *node, root bool) {
+	g.printf("\nfunc (x *%s) key(k string) string {\n", name)
+	g.printf("\tif x.prefix == \"\" {\n\t\treturn k\n\t}\n")
+	g.printf("\treturn x.prefix + %q + k\n}\n", g.cfg.Delim)
+
+	keys := make([]string, 0, len(n.fields))
+	for k := range n.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// keys such as "a_b" and "aB" map to the same name; later ones are
+	// numbered to keep the methods and types distinct
+	methods := map[string]bool{}
+	if root {
+		methods["Entity"] = true
+	}
+	var nested []string
+	types := make(map[string]string)
+	for _, k := range keys {
+		f := n.fields[k]
+		method := unique(exportedName(k), methods)
+		typ := name + method
+		if f.kind == "object" || f.kind == "array" && f.elem.kind == "object" {
+			typ = unique(typ, g.types)
+			types[k] = typ
+		}
+		path := fmt.Sprintf("x.key(%q)", k)
+
+		g.printf("\n// %s returns the value of %q.\n", method, k)
+		switch f.kind {
+		case "object":
+			g.printf("func (x *%s) %s() *%s {\n", name, method, typ)
+			g.printf("\treturn &%s{e: x.e, prefix: %s}\n}\n", typ, path)
+			g.declare(typ)
+			nested = append(nested, k)
+		case "array":
+			switch f.elem.kind {
+			case "object":
+				g.printf("func (x *%s) %s() []*%s {\n", name, method, typ)
+				g.printf("\tvar s []*%s\n", typ)
+				g.printf("\tfor _, el := range x.e.SubSlice(%s) {\n", path)
+				g.printf("\t\ts = append(s, &%s{e: el})\n\t}\n", typ)
+				g.printf("\treturn s\n}\n")
+				g.declare(typ)
+				nested = append(nested, k)
+			case "string":
+				g.printf("func (x *%s) %s() []string {\n\treturn x.e.GetStringSlice(%s)\n}\n", name, method, path)
+			case "int":
+				g.printf("func (x *%s) %s() []int {\n\treturn x.e.GetIntSlice(%s)\n}\n", name, method, path)
+			default:
+				g.printf("func (x *%s) %s() []interface{} {\n\treturn x.e.GetSlice(%s)\n}\n", name, method, path)
+			}
+		default:
+			goType, getter := scalar(f.kind)
+			g.printf("func (x *%s) %s() %s {\n\treturn x.e.%s(%s)\n}\n", name, method, goType, getter, path)
+		}
+	}
+
+	for _, k := range nested {
+		f := n.fields[k]
+		if f.kind == "array" {
+			f = f.elem
+		}
+		g.object(types[k], f, false)
+	}
+}
+
+// unique returns name, or name followed by the first number making it
+// absent from used, and marks the result as used.
+func unique(name string, used map[string]bool) string {
+	u := name
+	for i := 2; used[u]; i++ {
+		u = fmt.Sprintf("%s%d", name, i)
+	}
+	used[u] = true
+	return u
+}
+
+// declare emits the wrapper type typ.
+func (g *generator) declare(typ string) {
+	g.printf("\n// %s provides typed access to a nested object.\n", typ)
+	g.printf("type %s struct {\n\te *entity.Entity\n\tprefix string\n}\n", typ)
+}
+
+// exportedName converts a key such as "client_context" or "messageId" into
+// an exported Go identifier such as "ClientContext" or "MessageId".
+func exportedName(key string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range key {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
@@ -0,0 +1,132 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// build compiles the generated source src as a package of this module.
+func build(t *testing.T, src []byte) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping go build in short mode")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+	if err := os.MkdirAll("testdata", 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove("testdata") // only if empty
+	dir, err := ioutil.TempDir("testdata", "gen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "gen.go"), src, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := exec.Command(goTool, "build", "./"+filepath.ToSlash(dir)).CombinedOutput()
+	if err != nil {
+		t.Fatalf("generated code does not build: %v\n%s\n%s", err, out, src)
+	}
+}
+
+func TestGenerate_Sample(t *testing.T) {
+	src, err := Generate([]byte(`{
+		"server": {"port": 8080, "host": "localhost", "ratio": 0.5},
+		"debug": true,
+		"tags": ["a"],
+		"users": [{"name": "jack"}]
+	}`), Config{Package: "config", Type: "Config", Delim: ":"})
+	if err != nil {
+		t.Fatal("Generate error:", err)
+	}
+
+	for _, want := range []string{
+		"package config",
+		"func (x *Config) Server() *ConfigServer {",
+		"func (x *ConfigServer) Port() int {\n\treturn x.e.GetInt(x.key(\"port\"))",
+		"func (x *ConfigServer) Ratio() float64 {",
+		"func (x *Config) Debug() bool {",
+		"func (x *Config) Tags() []string {",
+		"func (x *Config) Users() []*ConfigUsers {",
+		"func (x *ConfigUsers) Name() string {",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generated code is missing %q:\n%s", want, src)
+		}
+	}
+	build(t, src)
+}
+
+func TestGenerate_Schema(t *testing.T) {
+	src, err := Generate([]byte(`{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type": "object",
+		"properties": {
+			"db": {"type": "object", "properties": {"max_conns": {"type": "integer"}}}
+		}
+	}`), Config{Package: "config", Type: "Config", Delim: "."})
+	if err != nil {
+		t.Fatal("Generate error:", err)
+	}
+
+	for _, want := range []string{
+		"func (x *Config) Db() *ConfigDb {",
+		"func (x *ConfigDb) MaxConns() int {",
+		`return x.prefix + "." + k`,
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generated code is missing %q:\n%s", want, src)
+		}
+	}
+	build(t, src)
+}
+
+func TestGenerate_Collisions(t *testing.T) {
+	src, err := Generate([]byte(`{
+		"entity": 1, "a_b": 1, "aB": 2,
+		"items": [{"x": {"y": 1}}], "items_x": {"z": 1}
+	}`), Config{Package: "config", Type: "Config", Delim: "."})
+	if err != nil {
+		t.Fatal("Generate error:", err)
+	}
+
+	for _, want := range []string{
+		"func (x *Config) AB() int {",
+		"func (x *Config) AB2() int {",
+		"func (x *Config) Entity2() int {",
+		"func (x *Config) ItemsX() *ConfigItemsX {",
+		"func (x *ConfigItemsX2) Y() int {",
+		`for _, el := range x.e.SubSlice(x.key("items")) {`,
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("generated code is missing %q:\n%s", want, src)
+		}
+	}
+	build(t, src)
+}
+
+func TestExportedName(t *testing.T) {
+	for key, want := range map[string]string{
+		"client_context": "ClientContext",
+		"messageId":      "MessageId",
+		"2fa":            "X2fa",
+	} {
+		if got := exportedName(key); got != want {
+			t.Errorf("exportedName(%q) is %q, not %q", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// Command entitygen generates typed accessors over an entity.Entity from a
+// sample JSON document or a JSON Schema.
+//
+// Usage:
+//
+//	entitygen -in config.json -type Config -package config -out config_gen.go
+//
+// or, from a go:generate directive:
+//
+//	//go:generate entitygen -in config.schema.json -type Config -out config_gen.go
+//
+// For a document like {"server": {"port": 8080}} the generated code allows
+// cfg.Server().Port(), backed by the "server:port" entity path.
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"log"
+	"os"
+)
+
+func main() {
+	var (
+		in    = flag.String("in", "", "sample JSON or JSON Schema `file` (default stdin)")
+		out   = flag.String("out", "", "output `file` (default stdout)")
+		name  = flag.String("type", "Entity", "name of the generated root type")
+		pkg   = flag.String("package", os.Getenv("GOPACKAGE"), "package of the generated code")
+		delim = flag.String("delim", ":", "key delimiter of the wrapped entity")
+	)
+	flag.Parse()
+	log.SetFlags(0)
+	log.SetPrefix("entitygen: ")
+
+	if *pkg == "" {
+		*pkg = "main"
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	if *in == "" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(*in)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := Generate(data, Config{Package: *pkg, Type: *name, Delim: *delim})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(src)
+	} else {
+		err = ioutil.WriteFile(*out, src, 0644)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
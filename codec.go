@@ -0,0 +1,188 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// Codec converts between a serialization format and Entity data.
+type Codec interface {
+	// Decode parses data into a map.
+	Decode(data []byte) (map[string]interface{}, error)
+	// Encode serializes a map.
+	Encode(data map[string]interface{}) ([]byte, error)
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]Codec{
+		"json": jsonCodec{},
+		"yaml": yamlCodec{},
+		"yml":  yamlCodec{},
+		"toml": tomlCodec{},
+	}
+)
+
+// RegisterCodec makes a Codec available under name for NewByFormat and
+// Encode, replacing any Codec previously registered under that name.
+// Names are case-insensitive.
+func RegisterCodec(name string, c Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+
+	codecs[strings.ToLower(name)] = c
+}
+
+// codecFor returns the Codec registered under name.
+func codecFor(name string) (Codec, error) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	c, ok := codecs[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q", name)
+	}
+	return c, nil
+}
+
+// NewByFormat returns an initialized Entity instance by data in the
+// format registered under name, e.g. "json", "yaml" or "toml".
+func NewByFormat(format string, data []byte, opts ...Option) (*Entity, error) {
+	c, err := codecFor(format)
+	if err != nil {
+		return nil, err
+	}
+	m, err := c.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	entity := New(m, opts...)
+	if entity.order != nil {
+		if err := entity.order.read(c, data, entity.normalizeKey); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := c.(yamlCodec); ok && entity.comments != nil {
+		readComments(entity.comments, data, entity.normalizeKey)
+	}
+	return entity, nil
+}
+
+// Encode serializes the Entity data in the format registered under name.
+func (entity *Entity) Encode(format string) ([]byte, error) {
+	c, err := codecFor(format)
+	if err != nil {
+		return nil, err
+	}
+
+	order, comments := entity.orderSnapshot(), entity.commentSnapshot()
+	data, done := entity.view()
+	defer done()
+
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	b, ok, err := order.encode(c, data)
+	if !ok {
+		b, err = c.Encode(data)
+	}
+	if _, ok := c.(yamlCodec); ok && err == nil {
+		b = writeComments(comments, b)
+	}
+	return b, err
+}
+
+// jsonCodec is the built-in Codec for "json".
+type jsonCodec struct{}
+
+func (jsonCodec) Decode(data []byte) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (jsonCodec) Encode(data map[string]interface{}) ([]byte, error) {
+	return json.Marshal(data)
+}
+
+// yamlCodec is the built-in Codec for "yaml" and "yml".
+type yamlCodec struct{}
+
+func (yamlCodec) Decode(data []byte) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return toStringKeys(m).(map[string]interface{}), nil
+}
+
+func (yamlCodec) Encode(data map[string]interface{}) ([]byte, error) {
+	return yaml.Marshal(data)
+}
+
+// toStringKeys converts the map[interface{}]interface{} values YAML decodes
+// into map[string]interface{}, recursively, so key paths resolve.
+func toStringKeys(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = toStringKeys(val)
+		}
+		return m
+	case map[string]interface{}:
+		for k, val := range v {
+			v[k] = toStringKeys(val)
+		}
+		return v
+	case []interface{}:
+		for i, val := range v {
+			v[i] = toStringKeys(val)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// tomlCodec is the built-in Codec for "toml".
+type tomlCodec struct{}
+
+func (tomlCodec) Decode(data []byte) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	if _, err := toml.Decode(string(data), &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (tomlCodec) Encode(data map[string]interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	if err := toml.NewEncoder(&b).Encode(data); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// ToYAML serializes the Entity data as YAML.
+func (entity *Entity) ToYAML() ([]byte, error) {
+	return entity.Encode("yaml")
+}
+
+// ToTOML serializes the Entity data as TOML.
+func (entity *Entity) ToTOML() ([]byte, error) {
+	return entity.Encode("toml")
+}
@@ -0,0 +1,147 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// propertiesCodec is a minimal key=value codec for testing RegisterCodec.
+type propertiesCodec struct{}
+
+func (propertiesCodec) Decode(data []byte) (map[string]interface{}, error) {
+	e := New(make(map[string]interface{}))
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if kv := strings.SplitN(scanner.Text(), "=", 2); len(kv) == 2 {
+			e.Set(kv[0], kv[1])
+		}
+	}
+	return e.GetData(), scanner.Err()
+}
+
+func (propertiesCodec) Encode(data map[string]interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	for k, v := range data {
+		fmt.Fprintf(&b, "%s=%v\n", k, v)
+	}
+	return b.Bytes(), nil
+}
+
+func TestNewByFormat(t *testing.T) {
+	e, err := NewByFormat("JSON", []byte(`{"admin": {"name": "jack"}}`))
+	if err != nil {
+		t.Fatal("NewByFormat json error:", err)
+	}
+	if e.GetString("admin:name") != "jack" {
+		t.Error("GetString 'admin:name' val is not jack")
+	}
+
+	if _, err := NewByFormat("ini", nil); err == nil {
+		t.Error("NewByFormat of an unknown format should fail")
+	}
+
+	RegisterCodec("properties", propertiesCodec{})
+	e, err = NewByFormat("properties", []byte("db:host=localhost\n"))
+	if err != nil {
+		t.Fatal("NewByFormat properties error:", err)
+	}
+	if e.GetString("db:host") != "localhost" {
+		t.Error("GetString 'db:host' val is not localhost")
+	}
+}
+
+func TestEntity_Encode(t *testing.T) {
+	e := New(map[string]interface{}{"name": "jack"})
+
+	b, err := e.Encode("json")
+	if err != nil {
+		t.Fatal("Encode json error:", err)
+	}
+	if string(b) != `{"name":"jack"}` {
+		t.Errorf("Encode json is %s", b)
+	}
+
+	RegisterCodec("properties", propertiesCodec{})
+	if b, _ := e.Encode("properties"); string(b) != "name=jack\n" {
+		t.Errorf("Encode properties is %q", b)
+	}
+}
+
+func TestNewByYAML(t *testing.T) {
+	e := NewByYAML([]byte(`
+defaults: &defaults
+  adapter: postgres
+  port: 5432
+development:
+  <<: *defaults
+  database: dev
+hosts: [*defaults]
+1: one
+`))
+
+	if e.GetString("development:adapter") != "postgres" || e.GetInt("development:port") != 5432 {
+		t.Errorf("merge key should expand, development is %v", e.Get("development"))
+	}
+	if e.GetString("hosts:0:adapter") != "postgres" {
+		t.Error("alias should expand inside arrays")
+	}
+	if _, ok := e.Get("development").(map[string]interface{}); !ok {
+		t.Errorf("nested maps should have string keys, got %T", e.Get("development"))
+	}
+	if e.GetString("1") != "one" {
+		t.Error("non-string keys should be converted to strings")
+	}
+}
+
+func TestEntity_ToYAML(t *testing.T) {
+	e := NewFromKV("a:b", 1, "list", []interface{}{"x"})
+
+	b, err := e.ToYAML()
+	if err != nil {
+		t.Fatal("ToYAML error:", err)
+	}
+	if string(b) != "a:\n  b: 1\nlist:\n- x\n" {
+		t.Errorf("ToYAML is %q", b)
+	}
+	if NewByYAML(b).GetInt("a:b") != 1 {
+		t.Error("ToYAML output should parse back")
+	}
+}
+
+func TestNewByTOML(t *testing.T) {
+	e := NewByTOML([]byte(`
+title = "example"
+
+[server]
+port = 8080
+
+[[users]]
+name = "jack"
+
+[[users]]
+name = "rose"
+`))
+
+	if e.GetString("title") != "example" || e.GetInt("server:port") != 8080 {
+		t.Errorf("NewByTOML data is %v", e.GetData())
+	}
+	if e.GetString("users:1:name") != "rose" {
+		t.Error("GetString 'users:1:name' val is not rose")
+	}
+
+	b, err := e.ToTOML()
+	if err != nil {
+		t.Fatal("ToTOML error:", err)
+	}
+	if back := NewByTOML(b); back.GetInt("server:port") != 8080 || back.GetString("users:0:name") != "jack" {
+		t.Errorf("ToTOML output should parse back, got %s", b)
+	}
+}
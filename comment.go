@@ -0,0 +1,336 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"bufio"
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// WithComments makes the Entity keep the comments of the YAML parsed by
+// NewByFormat: the comment lines directly above a key and the comment
+// following it on the same line. ToYAML and Encode with the built-in YAML
+// codec emit them again next to the keys still present. Comments are only
+// recognized in block-style YAML; see also Comment and SetComment.
+func WithComments() Option {
+	return func(entity *Entity) {
+		entity.comments = make(map[string]keyComment)
+	}
+}
+
+// keyComment holds the comments of a key, without the leading "#".
+type keyComment struct {
+	head string // lines above the key, joined with "\n"
+	line string // comment after the key on its line
+}
+
+// Comment returns the comment lines above the key, joined with "\n", or "".
+func (entity *Entity) Comment(key string) string {
+	entity.mu.RLock()
+	defer entity.mu.RUnlock()
+
+	return entity.comments[entity.commentKey(key)].head
+}
+
+// SetComment sets the comment lines above the key, separated by "\n", to be
+// emitted by ToYAML. An empty comment removes them. It enables comments on
+// an Entity created without WithComments.
+func (entity *Entity) SetComment(key, comment string) *Entity {
+	entity.mu.Lock()
+	defer entity.mu.Unlock()
+
+	if entity.comments == nil {
+		entity.comments = make(map[string]keyComment)
+	}
+	k := entity.commentKey(key)
+	c := entity.comments[k]
+	c.head = comment
+	if c == (keyComment{}) {
+		delete(entity.comments, k)
+	} else {
+		entity.comments[k] = c
+	}
+	return entity
+}
+
+// commentKey returns the path of the key joined with orderSep.
+func (entity *Entity) commentKey(key string) string {
+	delim := entity.keyDelim
+	if delim == "" {
+		delim = ":"
+	}
+	return strings.Join(strings.Split(entity.normalizeKey(key), delim), orderSep)
+}
+
+// commentSnapshot returns a copy of the comments of the Entity, or nil.
+func (entity *Entity) commentSnapshot() map[string]keyComment {
+	entity.mu.RLock()
+	defer entity.mu.RUnlock()
+
+	return copyComments(entity.comments)
+}
+
+// copyComments returns a copy of comments, or nil.
+func copyComments(comments map[string]keyComment) map[string]keyComment {
+	if comments == nil {
+		return nil
+	}
+	c := make(map[string]keyComment, len(comments))
+	for k, v := range comments {
+		c[k] = v
+	}
+	return c
+}
+
+// readComments records the comments of the keys of the YAML document data
+// in comments, passing the keys through normalize.
+func readComments(comments map[string]keyComment, data []byte, normalize func(string) string) {
+	var head []string
+	scanYAML(data, func(line string, l yamlLine) {
+		switch {
+		case l.comment:
+			head = append(head, l.text)
+			return
+		case l.path != nil:
+			path := make([]string, len(l.path))
+			for i, k := range l.path {
+				path[i] = normalize(k)
+			}
+			c := keyComment{head: strings.Join(head, "\n"), line: l.text}
+			if c != (keyComment{}) {
+				comments[strings.Join(path, orderSep)] = c
+			}
+		}
+		head = nil
+	})
+}
+
+// writeComments returns the YAML document data with comments inserted next
+// to the keys they belong to.
+func writeComments(comments map[string]keyComment, data []byte) []byte {
+	if len(comments) == 0 {
+		return data
+	}
+	var b bytes.Buffer
+	scanYAML(data, func(line string, l yamlLine) {
+		c, ok := comments[strings.Join(l.path, orderSep)]
+		if l.path == nil || !ok {
+			b.WriteString(line)
+			b.WriteByte('\n')
+			return
+		}
+		if c.head != "" {
+			indent := strings.Repeat(" ", l.indent)
+			for _, h := range strings.Split(c.head, "\n") {
+				b.WriteString(indent + "#" + prefixSpace(h) + "\n")
+			}
+		}
+		b.WriteString(line)
+		if c.line != "" {
+			b.WriteString(" #" + prefixSpace(c.line))
+		}
+		b.WriteByte('\n')
+	})
+	return b.Bytes()
+}
+
+// prefixSpace returns s preceded by a space unless it is empty.
+func prefixSpace(s string) string {
+	if s == "" {
+		return s
+	}
+	return " " + s
+}
+
+// yamlLine describes a line of a YAML document seen by scanYAML.
+type yamlLine struct {
+	// path of the key on the line, nil if there is none
+	path []string
+	// column of the first character of the line
+	indent int
+	// whether the line holds only a comment
+	comment bool
+	// text of the comment on the line, without "#" and the following space
+	text string
+}
+
+// yamlFrame is an open mapping key or sequence of a YAML document.
+type yamlFrame struct {
+	indent int
+	key    string
+	seq    bool
+	index  int
+}
+
+// scanYAML calls fn for each line of the block-style YAML document data,
+// tracking the path of the keys through nested mappings and sequences.
+func scanYAML(data []byte, fn func(line string, l yamlLine)) {
+	var stack []yamlFrame
+	block := -1 // indent of the key holding a block scalar being skipped
+	s := bufio.NewScanner(bytes.NewReader(data))
+	s.Buffer(nil, len(data)+1)
+	for s.Scan() {
+		line := strings.TrimRight(s.Text(), "\r")
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+		l := yamlLine{indent: indent}
+
+		if block >= 0 {
+			if trimmed == "" || indent > block {
+				fn(line, l)
+				continue
+			}
+			block = -1
+		}
+		switch {
+		case trimmed == "":
+			fn(line, l)
+			continue
+		case trimmed[0] == '#':
+			l.comment = true
+			l.text = strings.TrimPrefix(trimmed[1:], " ")
+			fn(line, l)
+			continue
+		case trimmed == "---" || trimmed == "...":
+			stack = nil
+			fn(line, l)
+			continue
+		}
+
+		col, rest := indent, trimmed
+		for rest == "-" || strings.HasPrefix(rest, "- ") {
+			for len(stack) > 0 && stack[len(stack)-1].indent > col {
+				stack = stack[:len(stack)-1]
+			}
+			if n := len(stack); n > 0 && stack[n-1].seq && stack[n-1].indent == col {
+				stack[n-1].index++
+			} else {
+				stack = append(stack, yamlFrame{indent: col, seq: true})
+			}
+			if rest == "-" {
+				rest = ""
+				break
+			}
+			item := strings.TrimLeft(rest[1:], " ")
+			col += len(rest) - len(item)
+			rest = item
+		}
+
+		key, value, ok := splitYAMLKey(rest)
+		if !ok {
+			fn(line, l)
+			continue
+		}
+		for len(stack) > 0 && stack[len(stack)-1].indent >= col {
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, yamlFrame{indent: col, key: key})
+
+		l.path = make([]string, len(stack))
+		for i, f := range stack {
+			if f.seq {
+				l.path[i] = strconv.Itoa(f.index)
+			} else {
+				l.path[i] = f.key
+			}
+		}
+		if i := commentIndex(value); i >= 0 {
+			l.text = strings.TrimPrefix(value[i+1:], " ")
+			value = value[:i]
+		}
+		if v := strings.TrimSpace(value); strings.HasPrefix(v, "|") || strings.HasPrefix(v, ">") {
+			block = col
+		}
+		fn(line, l)
+	}
+}
+
+// splitYAMLKey splits s, the content of a line after any sequence dashes,
+// into a mapping key and the rest of the line after the colon.
+func splitYAMLKey(s string) (key, rest string, ok bool) {
+	if s == "" {
+		return "", "", false
+	}
+	var end int
+	switch s[0] {
+	case '"':
+		for end = 1; end < len(s) && s[end] != '"'; end++ {
+			if s[end] == '\\' {
+				end++
+			}
+		}
+		if end >= len(s) {
+			return "", "", false
+		}
+		k, err := strconv.Unquote(s[:end+1])
+		if err != nil {
+			return "", "", false
+		}
+		key, end = k, end+1
+	case '\'':
+		for end = 1; end < len(s); end++ {
+			if s[end] == '\'' {
+				if end+1 < len(s) && s[end+1] == '\'' {
+					end++
+					continue
+				}
+				break
+			}
+		}
+		if end >= len(s) {
+			return "", "", false
+		}
+		key, end = strings.Replace(s[1:end], "''", "'", -1), end+1
+	case '{', '[', '#', '&', '*', '!', '|', '>', '%', '@', '`':
+		return "", "", false
+	default:
+		for end = 0; end < len(s); end++ {
+			if s[end] == ':' && (end+1 == len(s) || s[end+1] == ' ') {
+				break
+			}
+			if s[end] == '#' && end > 0 && s[end-1] == ' ' {
+				return "", "", false
+			}
+		}
+		if end == len(s) {
+			return "", "", false
+		}
+		key = strings.TrimRight(s[:end], " ")
+	}
+
+	rest = strings.TrimLeft(s[end:], " ")
+	if rest == "" || rest[0] != ':' || len(rest) > 1 && rest[1] != ' ' {
+		return "", "", false
+	}
+	return key, rest[1:], true
+}
+
+// commentIndex returns the index of the "#" starting a comment in the value
+// part s of a line, or -1.
+func commentIndex(s string) int {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || s[i-1] == ' ' {
+				quote = c
+			}
+		case c == '#':
+			if i == 0 || s[i-1] == ' ' || s[i-1] == '\t' {
+				return i
+			}
+		}
+	}
+	return -1
+}
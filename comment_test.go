@@ -0,0 +1,76 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"testing"
+)
+
+func TestWithComments(t *testing.T) {
+	src := `# database settings
+db:
+  # primary host
+  host: localhost # overridden in prod
+  port: 5432
+  note: |
+    # not a comment
+    text
+servers:
+# the first server
+- name: "a # b" # quoted hash
+  # weight of a
+  weight: 1
+- name: c
+  weight: 2
+`
+	e, err := NewByFormat("yaml", []byte(src), WithComments(), WithOrderedKeys())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := e.Comment("db"); got != "database settings" {
+		t.Errorf("Comment 'db' is %q", got)
+	}
+	if got := e.Comment("servers:0:weight"); got != "weight of a" {
+		t.Errorf("Comment 'servers:0:weight' is %q", got)
+	}
+	if got := e.Comment("db:note"); got != "" {
+		t.Errorf("Comment 'db:note' is %q", got)
+	}
+	if e.GetString("db:note") != "# not a comment\ntext\n" || e.GetString("servers:0:name") != "a # b" {
+		t.Errorf("comments should not change the data %v", e.GetData())
+	}
+
+	e.Set("db:port", 5433).SetComment("servers:1:name", "second\nserver")
+	e.Delete("db:host")
+	want := `# database settings
+db:
+  port: 5433
+  note: |
+    # not a comment
+    text
+servers:
+# the first server
+- name: 'a # b' # quoted hash
+  # weight of a
+  weight: 1
+# second
+# server
+- name: c
+  weight: 2
+`
+	b, err := e.ToYAML()
+	if err != nil || string(b) != want {
+		t.Errorf("ToYAML is\n%s%v", b, err)
+	}
+	if b, _ := e.Clone().ToYAML(); string(b) != want {
+		t.Errorf("Clone ToYAML is\n%s", b)
+	}
+
+	plain, _ := NewByFormat("yaml", []byte(src))
+	if plain.Comment("db") != "" {
+		t.Error("comments should only be kept WithComments")
+	}
+}
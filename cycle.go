@@ -0,0 +1,106 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrCircularReference is returned when a value to be set contains itself
+// or the map it is set into, which would make it infinitely deep.
+var ErrCircularReference = errors.New("circular reference")
+
+// container identifies a map or slice by kind and address.
+type container struct {
+	kind reflect.Kind
+	ptr  uintptr
+}
+
+// containerOf returns the identity of v if v is a non-empty map or slice.
+func containerOf(v interface{}) (container, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice:
+		if rv.Len() == 0 {
+			return container{}, false
+		}
+		return container{rv.Kind(), rv.Pointer()}, true
+	default:
+		return container{}, false
+	}
+}
+
+// checkCycle returns ErrCircularReference if value, once set at path,
+// would contain one of its own ancestors.
+func (entity *Entity) checkCycle(path []string, value interface{}) error {
+	if _, ok := containerOf(value); !ok {
+		return nil
+	}
+	ancestors := make(map[container]bool)
+	for i := range path {
+		c, ok := containerOf(entity.searchMap(entity.data, path[:i]))
+		if !ok {
+			break
+		}
+		ancestors[c] = true
+	}
+
+	if hasCycle(value, ancestors) {
+		return ErrCircularReference
+	}
+	return nil
+}
+
+// hasCycle reports whether v is, or contains, one of ancestors or itself.
+func hasCycle(v interface{}, ancestors map[container]bool) bool {
+	c, ok := containerOf(v)
+	if !ok {
+		return false
+	}
+	if ancestors[c] {
+		return true
+	}
+	ancestors[c] = true
+	defer delete(ancestors, c)
+
+	// the common decoded types are walked without reflection, which would
+	// allocate for every element
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, val := range v {
+			if hasCycle(val, ancestors) {
+				return true
+			}
+		}
+		return false
+	case []interface{}:
+		for _, val := range v {
+			if hasCycle(val, ancestors) {
+				return true
+			}
+		}
+		return false
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		iter := rv.MapRange()
+		for iter.Next() {
+			if hasCycle(iter.Value().Interface(), ancestors) {
+				return true
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < rv.Len(); i++ {
+			if hasCycle(rv.Index(i).Interface(), ancestors) {
+				return true
+			}
+		}
+	}
+	return false
+}
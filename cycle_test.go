@@ -0,0 +1,32 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"testing"
+)
+
+func TestEntity_SetE_CircularReference(t *testing.T) {
+	e := New(map[string]interface{}{"name": "jack"})
+
+	if err := e.SetE("list", []interface{}{e.GetData()}); err != ErrCircularReference {
+		t.Errorf("SetE of own data error is %v", err)
+	}
+
+	self := map[string]interface{}{"name": "loop"}
+	self["self"] = self
+	if err := e.SetE("a:b", self); err != ErrCircularReference {
+		t.Errorf("SetE of self-referencing map error is %v", err)
+	}
+	if e.Get("a:b") != nil || e.Get("list") != nil {
+		t.Error("SetE should not set a circular value")
+	}
+
+	shared := map[string]interface{}{"x": 1}
+	if err := e.SetE("pair", []interface{}{shared, shared}); err != nil {
+		t.Errorf("SetE of a shared, acyclic value error is %v", err)
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"log"
+	"strings"
+	"sync"
+)
+
+// SetDefault sets the value returned for the key when it is missing from the
+// Entity and its fallback chain.
+func (entity *Entity) SetDefault(key string, value interface{}) *Entity {
+	entity.mu.Lock()
+	defer entity.mu.Unlock()
+
+	if entity.keyDelim == "" {
+		entity.keyDelim = ":"
+	}
+	if entity.defaults == nil {
+		entity.defaults = make(map[string]interface{})
+	}
+	path := strings.Split(entity.normalizeKey(key), entity.keyDelim)
+	value = toCaseInsensitiveValue(value, entity.normalizeKey)
+	if _, err := setPath(entity.defaults, path, value); err != nil {
+		log.Println(err)
+	}
+	return entity
+}
+
+// SetFallback makes Get resolve keys missing from the Entity against
+// fallback, and in turn against the fallback of fallback, before using the
+// defaults of the Entity. A nil fallback removes it.
+// Objects are merged over the objects at the same key in the fallback chain
+// and the defaults, so Get, Sub, UnmarshalKey, Unmarshal, Keys, AllKeys,
+// Flatten and the serializers such as ToJSON all see the same layered view.
+// Merged objects are copies: changing them does not change the Entity.
+// It returns ErrCircularReference if the Entity is part of the fallback chain.
+func (entity *Entity) SetFallback(fallback *Entity) error {
+	// serialize changes of fallback chains so none can form a cycle between
+	// the check and the update
+	fallbackMu.Lock()
+	defer fallbackMu.Unlock()
+
+	for f := fallback; f != nil; f = f.Fallback() {
+		if f == entity {
+			return ErrCircularReference
+		}
+	}
+
+	entity.mu.Lock()
+	defer entity.mu.Unlock()
+
+	entity.fallback = fallback
+	return nil
+}
+
+// Fallback returns the fallback of the Entity, or nil.
+func (entity *Entity) Fallback() *Entity {
+	entity.mu.RLock()
+	defer entity.mu.RUnlock()
+
+	return entity.fallback
+}
+
+// fallbackMu guards the fallback chains against cycles, see SetFallback.
+var fallbackMu sync.Mutex
+
+// resolve returns the value for the key from the Entity, its fallback chain
+// or its defaults. An object is merged over the objects at the key in the
+// fallback chain and the defaults, like view does. The Entity is not locked
+// while the fallback is consulted.
+func (entity *Entity) resolve(key string) interface{} {
+	entity.evictExpired()
+	entity.mu.RLock()
+	key = entity.normalizeKey(key)
+	val := entity.find(key)
+	if entity.fallback == nil && entity.defaults == nil {
+		entity.mu.RUnlock()
+		return val
+	}
+	path := strings.Split(key, entity.keyDelim)
+	m, isMap := val.(map[string]interface{})
+	var c map[string]interface{}
+	if isMap {
+		c = deepCopy(m).(map[string]interface{})
+	}
+	entity.mu.RUnlock()
+
+	switch {
+	case isMap:
+		return entity.mergeDefault(path, m, c)
+	case val != nil:
+		return val
+	default:
+		return entity.resolveDefault(path)
+	}
+}
+
+// resolvePath returns the value at path, normalized by the caller, from the
+// Entity, its fallback chain or its defaults, merging objects like resolve.
+// Path elements may contain the key delimiter.
+func (entity *Entity) resolvePath(path []string) interface{} {
+	entity.evictExpired()
+	entity.mu.RLock()
+	if len(entity.keyTransforms) > 0 {
+		normalized := make([]string, len(path))
+		for i, k := range path {
+			normalized[i] = entity.normalizeKey(k)
+		}
+		path = normalized
+	}
+	val, ok := entity.getEnv(strings.Join(path, entity.keyDelim))
+	if !ok {
+		val = entity.searchMap(entity.data, path)
+	}
+	m, isMap := val.(map[string]interface{})
+	var c map[string]interface{}
+	if isMap {
+		c = deepCopy(m).(map[string]interface{})
+	}
+	entity.mu.RUnlock()
+
+	switch {
+	case isMap:
+		return entity.mergeDefault(path, m, c)
+	case val != nil:
+		return val
+	default:
+		return entity.resolveDefault(path)
+	}
+}
+
+// resolveDefault returns the value at the normalized path from the fallback
+// chain or the defaults, merging an object of the fallback chain over the
+// object of the defaults, unless the data of the Entity shadows the path
+// with a scalar. The Entity must not be locked.
+func (entity *Entity) resolveDefault(path []string) interface{} {
+	entity.mu.RLock()
+	if len(path) > 1 && entity.isPathShadowedInDeepMap(path, entity.data) != "" {
+		entity.mu.RUnlock()
+		return nil
+	}
+	fallback := entity.fallback
+	var def interface{}
+	if entity.defaults != nil {
+		def = deepCopy(entity.searchMap(entity.defaults, path))
+	}
+	entity.mu.RUnlock()
+
+	if fallback == nil {
+		return def
+	}
+	val := fallback.resolvePath(path)
+	if val == nil {
+		return def
+	}
+	dm, ok := def.(map[string]interface{})
+	m, isMap := val.(map[string]interface{})
+	if !ok || !isMap {
+		return val
+	}
+	mergeInto(dm, m)
+	return dm
+}
+
+// mergeDefault returns c, the copy of the object m at the normalized path,
+// merged over the object at the path in the fallback chain and the
+// defaults, or m itself if there is none. The Entity must not be locked.
+func (entity *Entity) mergeDefault(path []string, m, c map[string]interface{}) interface{} {
+	lower, ok := entity.resolveDefault(path).(map[string]interface{})
+	if !ok {
+		return m
+	}
+	mergeInto(lower, c)
+	return lower
+}
+
+// view returns the data of the Entity as Get sees it, merged over its
+// fallback chain and defaults, and a func to call once done reading it.
+func (entity *Entity) view() (map[string]interface{}, func()) {
+	if data, ok := entity.layered(); ok {
+		return data, func() {}
+	}
+	entity.mu.RLock()
+	return entity.data, entity.mu.RUnlock
+}
+
+// layered returns a copy of the data of the Entity merged over its fallback
+// chain and defaults, or false if it has neither.
+func (entity *Entity) layered() (map[string]interface{}, bool) {
+	entity.evictExpired()
+	entity.mu.RLock()
+	if entity.fallback == nil && entity.defaults == nil {
+		entity.mu.RUnlock()
+		return nil, false
+	}
+	data, _ := deepCopy(entity.data).(map[string]interface{})
+	view, _ := deepCopy(entity.defaults).(map[string]interface{})
+	fallback := entity.fallback
+	entity.mu.RUnlock()
+
+	if view == nil {
+		view = make(map[string]interface{})
+	}
+	if fallback != nil {
+		fv, ok := fallback.layered()
+		if !ok {
+			fv = fallback.snapshot()
+		}
+		mergeInto(view, fv)
+	}
+	mergeInto(view, data)
+	return view, true
+}
+
+// mergeInto deep merges src into dst, without copying.
+func mergeInto(dst, src map[string]interface{}) {
+	for k, v := range src {
+		if sm, ok := v.(map[string]interface{}); ok {
+			if dm, ok := dst[k].(map[string]interface{}); ok {
+				mergeInto(dm, sm)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestEntity_SetDefault(t *testing.T) {
+	e := NewByJSON([]byte(`{"db": {"host": "db.prod"}}`))
+	e.SetDefault("db:host", "localhost").SetDefault("db:port", 5432)
+
+	if e.GetString("db:host") != "db.prod" {
+		t.Error("a set value should win over its default")
+	}
+	if e.GetInt("db:port") != 5432 || !e.Has("db:port") {
+		t.Error("a missing key should resolve to its default")
+	}
+	if v, err := e.GetIntE("db:port"); err != nil || v != 5432 {
+		t.Errorf("GetIntE 'db:port' is %v, %v", v, err)
+	}
+
+	e.Delete("db:host")
+	if e.GetString("db:host") != "localhost" {
+		t.Error("a deleted key should resolve to its default")
+	}
+}
+
+func TestEntity_SetFallback(t *testing.T) {
+	defaults := NewFromKV("server:port", 80, "server:host", "0.0.0.0", "debug", false)
+	file := New(map[string]interface{}{"server": map[string]interface{}{"port": 8080}}, WithKeyDelim("."))
+	env := NewFromKV("debug", true)
+	env.SetDefault("log:level", "info")
+
+	if err := file.SetFallback(defaults); err != nil {
+		t.Fatal("SetFallback error:", err)
+	}
+	if err := env.SetFallback(file); err != nil {
+		t.Fatal("SetFallback error:", err)
+	}
+
+	if !env.GetBool("debug") || env.GetInt("server:port") != 8080 || env.GetString("server:host") != "0.0.0.0" {
+		t.Error("Get should walk the fallback chain in order")
+	}
+	if env.GetString("log:level") != "info" {
+		t.Error("Get should use the defaults after the fallback chain")
+	}
+	if env.Get("missing") != nil || env.Has("missing") {
+		t.Error("a key missing from the whole chain should be nil")
+	}
+
+	if err := defaults.SetFallback(env); err != ErrCircularReference {
+		t.Errorf("SetFallback of a cycle error is %v", err)
+	}
+	if env.SetFallback(nil); env.Get("server:port") != nil {
+		t.Error("SetFallback of nil should remove the fallback")
+	}
+}
+
+func TestEntity_FallbackView(t *testing.T) {
+	defaults := New(map[string]interface{}{
+		"server": map[string]interface{}{"port": 80, "host": "0.0.0.0"},
+		"redis":  map[string]interface{}{"user:1": "jack"},
+	})
+	e := NewFromKV("server:port", 8080)
+	e.SetDefault("debug", false)
+	if err := e.SetFallback(defaults); err != nil {
+		t.Fatal("SetFallback error:", err)
+	}
+
+	if e.GetString("redis:user:1") != "" {
+		t.Error("a key containing the delimiter should not be split in the fallback")
+	}
+	if v := e.resolveDefault([]string{"redis", "user:1"}); v != "jack" {
+		t.Errorf("the fallback should be looked up by path, got %v", v)
+	}
+
+	if keys := e.AllKeys(); !reflect.DeepEqual(keys, []string{"debug", "redis:user:1", "server:host", "server:port"}) {
+		t.Errorf("AllKeys is %v", keys)
+	}
+	if keys := e.Keys(); !reflect.DeepEqual(keys, []string{"debug", "redis", "server"}) {
+		t.Errorf("Keys is %v", keys)
+	}
+	if flat := e.Flatten(); flat["server:port"] != 8080 || flat["server:host"] != "0.0.0.0" {
+		t.Errorf("Flatten is %v", flat)
+	}
+	if b, _ := e.ToJSON(); string(b) != `{"debug":false,"redis":{"user:1":"jack"},"server":{"host":"0.0.0.0","port":8080}}` {
+		t.Errorf("ToJSON is %s", b)
+	}
+
+	var cfg struct {
+		Debug  bool
+		Server struct {
+			Host string
+			Port int
+		}
+	}
+	if err := e.Unmarshal(&cfg); err != nil || cfg.Server.Port != 8080 || cfg.Server.Host != "0.0.0.0" {
+		t.Errorf("Unmarshal is %+v, %v", cfg, err)
+	}
+}
+
+func TestEntity_FallbackObjects(t *testing.T) {
+	base := New(map[string]interface{}{"a": map[string]interface{}{"b": 1, "c": 2}})
+	e := New(map[string]interface{}{"a": map[string]interface{}{"b": 10}})
+	e.SetDefault("a:d", 4).SetDefault("a:c", 3)
+	if err := e.SetFallback(base); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]interface{}{"b": 10, "c": 2, "d": 4}
+	if got := e.GetStringMap("a"); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetStringMap of a layered object is %v", got)
+	}
+	if got := e.Sub("a").GetInt("c"); got != 2 {
+		t.Errorf("Sub of a layered object gives c = %d", got)
+	}
+	var cfg struct{ B, C, D int }
+	if err := e.UnmarshalKey("a", &cfg); err != nil || cfg.B != 10 || cfg.C != 2 || cfg.D != 4 {
+		t.Errorf("UnmarshalKey is %+v, %v", cfg, err)
+	}
+	if err := e.Unmarshal(&struct{ A *struct{ B, C, D int } }{}); err != nil {
+		t.Error(err)
+	}
+
+	e.GetStringMap("a")["b"] = 0
+	if e.GetInt("a:b") != 10 {
+		t.Error("changing a merged object should not change the Entity")
+	}
+
+	plain := New(map[string]interface{}{"x": map[string]interface{}{"y": 1}})
+	plain.SetDefault("z", 1)
+	plain.Sub("x").Set("y", 2)
+	if plain.GetInt("x:y") != 2 {
+		t.Error("Sub of an object without lower layers should share its data")
+	}
+}
+
+func TestEntity_SetFallbackConcurrent(t *testing.T) {
+	a, b := New(nil), New(nil)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		a.SetFallback(b)
+	}()
+	go func() {
+		defer wg.Done()
+		b.SetFallback(a)
+	}()
+	wg.Wait()
+
+	if a.Fallback() != nil && b.Fallback() != nil {
+		t.Fatal("concurrent SetFallback calls should not form a cycle")
+	}
+	a.Get("missing")
+	b.Get("missing")
+}
@@ -0,0 +1,120 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// Style selects the output of FormatDuration.
+type Style int
+
+const (
+	// StyleCompact formats durations like "2d5h30m".
+	StyleCompact Style = iota
+	// StyleLong formats durations like "2 days 5 hours 30 minutes".
+	StyleLong
+)
+
+// durationUnits lists the units used by FormatDuration, largest first.
+var durationUnits = []struct {
+	d     time.Duration
+	short string
+	long  string
+}{
+	{24 * time.Hour, "d", "day"},
+	{time.Hour, "h", "hour"},
+	{time.Minute, "m", "minute"},
+	{time.Second, "s", "second"},
+	{time.Millisecond, "ms", "millisecond"},
+}
+
+// FormatDuration renders d for humans in the given style, e.g. "2d5h" or
+// "2 days 5 hours". Zero units are omitted and anything below a millisecond
+// is dropped. GetDuration and Unmarshal parse the compact style back.
+func FormatDuration(d time.Duration, style Style) string {
+	sign := ""
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+
+	var parts []string
+	for _, unit := range durationUnits {
+		n := d / unit.d
+		if n == 0 {
+			continue
+		}
+		d -= n * unit.d
+		parts = append(parts, formatUnit(int64(n), unit.short, unit.long, style))
+	}
+
+	if len(parts) == 0 {
+		return formatUnit(0, "s", "second", style)
+	}
+	if style == StyleLong {
+		return sign + strings.Join(parts, " ")
+	}
+	return sign + strings.Join(parts, "")
+}
+
+func formatUnit(n int64, short, long string, style Style) string {
+	if style != StyleLong {
+		return strconv.FormatInt(n, 10) + short
+	}
+	if n == 1 {
+		return "1 " + long
+	}
+	return strconv.FormatInt(n, 10) + " " + long + "s"
+}
+
+// toDurationE converts i to a duration like cast.ToDurationE, also accepting
+// strings with a leading day unit, as FormatDuration emits, e.g. "2d5h".
+func toDurationE(i interface{}) (time.Duration, error) {
+	s, ok := i.(string)
+	if !ok {
+		return cast.ToDurationE(i)
+	}
+	idx := strings.IndexByte(s, 'd')
+	if idx < 0 {
+		return cast.ToDurationE(s)
+	}
+
+	days, sign := s[:idx], time.Duration(1)
+	if strings.HasPrefix(days, "-") {
+		days, sign = days[1:], -1
+	} else {
+		days = strings.TrimPrefix(days, "+")
+	}
+	n, err := strconv.ParseInt(days, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	d := time.Duration(n) * 24 * time.Hour
+	if rest := s[idx+1:]; rest != "" {
+		r, err := time.ParseDuration(rest)
+		if err != nil || r < 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		d += r
+	}
+	return sign * d, nil
+}
+
+// stringToDurationHook is a mapstructure decode hook converting strings to
+// durations with toDurationE.
+func stringToDurationHook(f, t reflect.Type, data interface{}) (interface{}, error) {
+	if f.Kind() != reflect.String || t != reflect.TypeOf(time.Duration(0)) {
+		return data, nil
+	}
+	return toDurationE(data)
+}
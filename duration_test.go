@@ -0,0 +1,57 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatDuration(t *testing.T) {
+	for _, tt := range []struct {
+		d            time.Duration
+		compact, lng string
+	}{
+		{53 * time.Hour, "2d5h", "2 days 5 hours"},
+		{time.Hour + time.Second, "1h1s", "1 hour 1 second"},
+		{1500 * time.Millisecond, "1s500ms", "1 second 500 milliseconds"},
+		{-90 * time.Second, "-1m30s", "-1 minute 30 seconds"},
+		{0, "0s", "0 seconds"},
+		{time.Microsecond, "0s", "0 seconds"},
+	} {
+		if got := FormatDuration(tt.d, StyleCompact); got != tt.compact {
+			t.Errorf("FormatDuration(%v, StyleCompact) is %q, not %q", tt.d, got, tt.compact)
+		}
+		if got := FormatDuration(tt.d, StyleLong); got != tt.lng {
+			t.Errorf("FormatDuration(%v, StyleLong) is %q, not %q", tt.d, got, tt.lng)
+		}
+	}
+}
+
+func TestEntity_GetDurationDays(t *testing.T) {
+	for _, d := range []time.Duration{53 * time.Hour, -49*time.Hour - time.Second, 24 * time.Hour, 1500 * time.Millisecond} {
+		e := New(map[string]interface{}{"d": FormatDuration(d, StyleCompact)})
+		if got := e.GetDuration("d"); got != d {
+			t.Errorf("GetDuration(%q) is %v, not %v", e.GetString("d"), got, d)
+		}
+	}
+
+	e := New(map[string]interface{}{"ok": "1d2h", "bad": "xd", "plain": "90m", "n": 5})
+	if d, err := e.GetDurationE("ok"); err != nil || d != 26*time.Hour {
+		t.Errorf("GetDurationE 'ok' is %v, %v", d, err)
+	}
+	if _, err := e.GetDurationE("bad"); err == nil {
+		t.Error("GetDurationE of an invalid day count should fail")
+	}
+	if e.GetDuration("plain") != 90*time.Minute || e.GetDuration("n") != 5 {
+		t.Error("GetDuration should keep parsing durations without days")
+	}
+
+	var c struct{ Timeout time.Duration }
+	if err := New(map[string]interface{}{"timeout": "2d"}).Unmarshal(&c); err != nil || c.Timeout != 48*time.Hour {
+		t.Errorf("Unmarshal of a day duration is %v, %v", c.Timeout, err)
+	}
+}
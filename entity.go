@@ -6,9 +6,14 @@
 package entity // import "github.com/lyf-coder/entity"
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 
@@ -22,106 +27,388 @@ type Entity struct {
 	keyDelim string
 
 	data map[string]interface{}
+
+	// environment variables bound to keys
+	env map[string]envBinding
+
+	// expiry times of keys set with a TTL
+	expires map[string]time.Time
+
+	// modification times of keys, recorded when enabled by WithModTimes
+	modTimes map[string]time.Time
+
+	// values returned for keys missing from data, see SetDefault
+	defaults map[string]interface{}
+
+	// Entity consulted for keys missing from data, see SetFallback
+	fallback *Entity
+
+	// callbacks registered with Watch, and the events queued for them while
+	// the Entity is locked
+	watchers    map[uint64]*watcher
+	nextWatcher uint64
+	events      []watchEvent
+
+	// paths and values of keys before they were changed, recorded when
+	// enabled by WithChangeTracking
+	changes map[string]tracked
+
+	// number of modifications so far
+	version uint64
+
+	// transforms applied to keys on ingestion and lookup
+	keyTransforms []func(string) string
+
+	// store maps as given instead of copying them, see WithCaseSensitive
+	noCopy bool
+
+	// expand environment variables in strings on read, see WithEnvExpansion
+	expandEnv bool
+
+	// order of the keys of the objects, recorded when enabled by
+	// WithOrderedKeys
+	order *keyOrder
+
+	// comments of the keys by path, kept when enabled by WithComments
+	comments map[string]keyComment
+
+	mu sync.RWMutex
 }
 
 // GetData return entity.data
 func (entity *Entity) GetData() map[string]interface{} {
+	entity.evictExpired()
 	return entity.data
 }
 
-// New returns an initialized Entity instance.
-func New(data map[string]interface{}) *Entity {
+// Option configures an Entity created by New.
+type Option func(entity *Entity)
+
+// WithKeyDelim sets the delimiter separating the keys of a path, ":" by
+// default. An empty delim is ignored.
+func WithKeyDelim(delim string) Option {
+	return func(entity *Entity) {
+		if delim != "" {
+			entity.keyDelim = delim
+		}
+	}
+}
+
+// New returns an initialized Entity instance over data, which it uses
+// without copying unless options that transform keys are given.
+// Note that New modifies data: nested maps with interface{} keys, as produced
+// by some decoders, are replaced within data and its arrays by
+// map[string]interface{} copies so lookups need not convert them again.
+// Pass a copy of data if the caller must keep the original maps.
+func New(data map[string]interface{}, opts ...Option) *Entity {
 	entity := new(Entity)
 	entity.keyDelim = ":"
 	entity.data = data
+	for _, opt := range opts {
+		opt(entity)
+	}
+	if data != nil && len(entity.keyTransforms) > 0 {
+		entity.data = copyAndInsensitiveMap(data, entity.normalizeKey)
+	} else {
+		normalizeMaps(data)
+	}
 	return entity
 }
 
+// child returns an Entity over data that shares the settings of entity.
+// Unlike New, it does not walk data, which the Entity has already
+// normalized.
+func (entity *Entity) child(data map[string]interface{}) *Entity {
+	c := &Entity{keyDelim: ":", data: data}
+	if entity.keyDelim != "" {
+		c.keyDelim = entity.keyDelim
+	}
+	c.keyTransforms = entity.keyTransforms
+	c.noCopy = entity.noCopy
+	c.expandEnv = entity.expandEnv
+	return c
+}
+
 // NewByJSON returns an initialized Entity instance by json byte[].
 func NewByJSON(data []byte) *Entity {
-	mapData := make(map[string]interface{})
-	err := json.Unmarshal(data, &mapData)
+	mapData, err := jsonCodec{}.Decode(data)
 	if err != nil {
 		log.Println(err)
+		mapData = make(map[string]interface{})
 	}
 	return New(mapData)
 }
 
-// deepSearch scans deep maps, following the key indexes listed in the
-// sequence "path".
-// The last value is expected to be another map, and is returned.
-//
-// In case intermediate keys do not exist, or map to a non-map value,
-// a new map is created and inserted, and the search continues from there:
-// the initial map "m" may be modified!
-func deepSearch(m map[string]interface{}, path []string) map[string]interface{} {
-	for _, k := range path {
-		m2, ok := m[k]
+// NewByYAML returns an initialized Entity instance by yaml byte[].
+func NewByYAML(data []byte) *Entity {
+	mapData, err := yamlCodec{}.Decode(data)
+	if err != nil {
+		log.Println(err)
+		mapData = make(map[string]interface{})
+	}
+	return New(mapData)
+}
+
+// NewByTOML returns an initialized Entity instance by toml byte[].
+func NewByTOML(data []byte) *Entity {
+	mapData, err := tomlCodec{}.Decode(data)
+	if err != nil {
+		log.Println(err)
+		mapData = make(map[string]interface{})
+	}
+	return New(mapData)
+}
+
+// NewFromKV returns an initialized Entity instance set from alternating
+// key/value arguments, e.g. NewFromKV("a:b", 1, "a:c", "x").
+func NewFromKV(kv ...interface{}) *Entity {
+	entity := New(make(map[string]interface{}))
+	if len(kv)%2 != 0 {
+		log.Printf("NewFromKV: odd number of arguments, ignoring %#v", kv[len(kv)-1])
+		kv = kv[:len(kv)-1]
+	}
+	for i := 0; i < len(kv); i += 2 {
+		key, ok := kv[i].(string)
 		if !ok {
-			// intermediate key does not exist
-			// => create it and continue from there
-			m3 := make(map[string]interface{})
-			m[k] = m3
-			m = m3
+			log.Printf("NewFromKV: key %#v of type %T is not a string", kv[i], kv[i])
 			continue
 		}
-		m3, ok := m2.(map[string]interface{})
-		if !ok {
-			// intermediate key is a value
-			// => replace with a new map
-			m3 = make(map[string]interface{})
-			m[k] = m3
+		entity.Set(key, kv[i+1])
+	}
+	return entity
+}
+
+// NewFromStringMap returns an initialized Entity instance from a map of
+// strings such as HTTP headers or labels. Values are parsed leniently:
+// "true" and "false" become booleans, numbers written as in JSON become
+// int64 or float64, everything else, including "NaN" or "01234", stays a
+// string.
+func NewFromStringMap(m map[string]string) *Entity {
+	entity := New(make(map[string]interface{}))
+	for key, val := range m {
+		entity.Set(key, parseLenient(val))
+	}
+	return entity
+}
+
+// parseLenient converts s to a bool or number if it holds one. Only numbers
+// written as in JSON are converted, so values such as "NaN", "Inf" or
+// "01234" stay strings.
+func parseLenient(s string) interface{} {
+	switch strings.ToLower(s) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if s == "" || s[0] != '-' && (s[0] < '0' || s[0] > '9') || !json.Valid([]byte(s)) {
+		return s
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return s
+}
+
+// setPath sets value at path inside node, following the key indexes listed
+// in the sequence "path" through nested maps and arrays, and returns the
+// updated node.
+//
+// In case intermediate keys do not exist, or map to a non-container value,
+// a new map is created and inserted, and the search continues from there:
+// the initial node may be modified!
+// An array index may address an existing element or, being equal to the
+// array length, append a new one.
+func setPath(node interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		v, err := setPath(n[path[0]], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n[path[0]] = v
+		return n, nil
+	case map[interface{}]interface{}:
+		v, err := setPath(n[path[0]], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		n[path[0]] = v
+		return n, nil
+	case []map[string]interface{}:
+		return setPath(cast.ToSlice(n), path, value)
+	case []interface{}:
+		i, err := strconv.Atoi(path[0])
+		if err != nil || i < 0 || i > len(n) {
+			return nil, fmt.Errorf("index %q out of range for array of length %d", path[0], len(n))
+		}
+		var elem interface{}
+		if i < len(n) {
+			elem = n[i]
+		}
+		v, err := setPath(elem, path[1:], value)
+		if err != nil {
+			return nil, err
 		}
-		// continue search from here
-		m = m3
+		if i == len(n) {
+			return append(n, v), nil
+		}
+		n[i] = v
+		return n, nil
+	default:
+		// intermediate key does not exist or is a value
+		// => replace with a new map and continue from there
+		return setPath(make(map[string]interface{}), path, value)
 	}
-	return m
 }
 
 // Set sets the value for the key in the Entity
 func (entity *Entity) Set(key string, value interface{}) *Entity {
-	value = toCaseInsensitiveValue(value)
-	if entity.data == nil {
-		entity.data = make(map[string]interface{})
+	if err := entity.SetE(key, value); err != nil {
+		log.Println(err)
+	}
+	return entity
+}
+
+// SetE sets the value for the key in the Entity like Set, but returns
+// ErrCircularReference instead of setting a value that contains itself
+// or the map it is set into.
+func (entity *Entity) SetE(key string, value interface{}) error {
+	entity.mu.Lock()
+	defer entity.unlock()
+
+	entity.clearTTL(key)
+	return entity.set(key, value)
+}
+
+// errNoChange is returned by update callbacks to leave the value as is.
+var errNoChange = errors.New("no change")
+
+// update replaces the value for the key with the result of fn,
+// keeping any TTL of the key.
+func (entity *Entity) update(key string, fn func(val interface{}) (interface{}, error)) error {
+	entity.evictExpired()
+	entity.mu.Lock()
+	defer entity.unlock()
+
+	val, err := fn(entity.find(key))
+	if err == errNoChange {
+		return nil
 	}
+	if err != nil {
+		return err
+	}
+	return entity.set(key, val)
+}
+
+// set sets the value for the key without locking.
+func (entity *Entity) set(key string, value interface{}) error {
 	if entity.keyDelim == "" {
 		entity.keyDelim = ":"
 	}
 
-	path := strings.Split(key, entity.keyDelim)
-	lastKey := path[len(path)-1]
+	key = entity.normalizeKey(key)
+	return entity.assign(key, strings.Split(key, entity.keyDelim), value)
+}
+
+// assign sets the value at the normalized path, joined as key, without
+// locking.
+func (entity *Entity) assign(key string, path []string, value interface{}) error {
+	if entity.data == nil {
+		entity.data = make(map[string]interface{})
+	}
 
-	deepestMap := deepSearch(entity.data, path[0:len(path)-1])
+	if err := entity.checkCycle(path, value); err != nil {
+		return err
+	}
+	value = entity.copyValue(value)
 
-	// set innermost value
-	deepestMap[lastKey] = value
+	entity.track(key, path)
+	old := entity.watchedValue(path)
+	if _, err := setPath(entity.data, path, value); err != nil {
+		return err
+	}
+	entity.order.add(path)
 
-	return entity
+	entity.touch(key)
+	entity.notify(key, old, value)
+	return nil
+}
+
+// copyValue returns value as the Entity stores it: maps are copied with
+// their keys normalized, unless the Entity was created WithCaseSensitive.
+func (entity *Entity) copyValue(value interface{}) interface{} {
+	if len(entity.keyTransforms) > 0 {
+		return toCaseInsensitiveValue(value, entity.normalizeKey)
+	}
+	if entity.noCopy {
+		return value
+	}
+	return toCaseInsensitiveValue(value, nil)
+}
+
+// replaceData replaces the data of the Entity with m without locking,
+// recording a change of each top-level key whose value differs.
+func (entity *Entity) replaceData(m map[string]interface{}) {
+	var changed []string
+	for k, v := range entity.data {
+		if nv, ok := m[k]; !ok || !reflect.DeepEqual(v, nv) {
+			changed = append(changed, k)
+		}
+	}
+	for k := range m {
+		if _, ok := entity.data[k]; !ok {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+
+	old := entity.data
+	for _, k := range changed {
+		entity.clearTTL(k)
+		entity.track(k, []string{k})
+	}
+	entity.data = m
+	for _, k := range changed {
+		entity.touch(k)
+		entity.notify(k, old[k], m[k])
+	}
 }
 
 // toCaseInsensitiveValue checks if the value is a  map;
 // if so, create a copy and recursively.
-func toCaseInsensitiveValue(value interface{}) interface{} {
+func toCaseInsensitiveValue(value interface{}, normalize func(string) string) interface{} {
 	switch v := value.(type) {
 	case map[interface{}]interface{}:
-		value = copyAndInsensitiveMap(cast.ToStringMap(v))
+		value = copyAndInsensitiveMap(cast.ToStringMap(v), normalize)
 	case map[string]interface{}:
-		value = copyAndInsensitiveMap(v)
+		value = copyAndInsensitiveMap(v, normalize)
 	}
 
 	return value
 }
 
-// copyAndInsensitiveMap  creates a copy of any map it makes case insensitive.
-func copyAndInsensitiveMap(m map[string]interface{}) map[string]interface{} {
-	nm := make(map[string]interface{})
+// copyAndInsensitiveMap  creates a copy of any map it makes case insensitive,
+// passing its keys through normalize unless it is nil.
+func copyAndInsensitiveMap(m map[string]interface{}, normalize func(string) string) map[string]interface{} {
+	nm := make(map[string]interface{}, len(m))
 
 	for key, val := range m {
+		if normalize != nil {
+			key = normalize(key)
+		}
 		switch v := val.(type) {
 		case map[interface{}]interface{}:
-			nm[key] = copyAndInsensitiveMap(cast.ToStringMap(v))
+			nm[key] = copyAndInsensitiveMap(cast.ToStringMap(v), normalize)
 		case map[string]interface{}:
-			nm[key] = copyAndInsensitiveMap(v)
+			nm[key] = copyAndInsensitiveMap(v, normalize)
 		default:
 			nm[key] = v
 		}
@@ -130,6 +417,90 @@ func copyAndInsensitiveMap(m map[string]interface{}) map[string]interface{} {
 	return nm
 }
 
+// normalizeMaps replaces the maps with interface{} keys nested in v with
+// map[string]interface{} copies, in place.
+func normalizeMaps(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			if m, ok := val.(map[interface{}]interface{}); ok {
+				val = cast.ToStringMap(m)
+				v[k] = val
+			}
+			normalizeMaps(val)
+		}
+	case []interface{}:
+		for i, val := range v {
+			if m, ok := val.(map[interface{}]interface{}); ok {
+				val = cast.ToStringMap(m)
+				v[i] = val
+			}
+			normalizeMaps(val)
+		}
+	case []map[string]interface{}:
+		for _, m := range v {
+			normalizeMaps(m)
+		}
+	}
+}
+
+// searchKey searches for the value for key in source, splitting key on the
+// key delimiter as it goes rather than allocating a path.
+// Returns nil if not found.
+func (entity *Entity) searchKey(source map[string]interface{}, key string) interface{} {
+	var v interface{} = source
+	for {
+		head, rest, i := key, "", -1
+		if entity.keyDelim != "" {
+			i = strings.Index(key, entity.keyDelim)
+		}
+		if i >= 0 {
+			head, rest = key[:i], key[i+len(entity.keyDelim):]
+		}
+
+		switch c := v.(type) {
+		case map[string]interface{}:
+			v = c[head]
+		case map[interface{}]interface{}:
+			v = c[head]
+		case []interface{}:
+			idx, ok := index(head, len(c))
+			if !ok {
+				return nil
+			}
+			v = c[idx]
+		case []map[string]interface{}:
+			idx, ok := index(head, len(c))
+			if !ok {
+				return nil
+			}
+			v = c[idx]
+		default:
+			return nil
+		}
+
+		if i < 0 || v == nil {
+			return v
+		}
+		key = rest
+	}
+}
+
+// index parses s as an index into an array of length n.
+func index(s string, n int) (int, bool) {
+	if s == "" || len(s) > 18 {
+		return 0, false
+	}
+	i := 0
+	for _, c := range []byte(s) {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		i = i*10 + int(c-'0')
+	}
+	return i, i < n
+}
+
 // searchMap recursively searches for a value for path in source map.
 // Returns nil if not found.
 func (entity *Entity) searchMap(source map[string]interface{}, path []string) interface{} {
@@ -145,19 +516,46 @@ func (entity *Entity) searchMap(source map[string]interface{}, path []string) in
 		}
 
 		// Nested case
-		switch next := next.(type) {
-		case map[interface{}]interface{}:
-			return entity.searchMap(cast.ToStringMap(next), path[1:])
-		case map[string]interface{}:
-			// Type assertion is safe here since it is only reached
-			// if the type of `next` is the same as the type being asserted
-			return entity.searchMap(next, path[1:])
-		default:
-			// got a value but nested key expected, return "nil" for not found
+		return entity.searchValue(next, path[1:])
+	}
+	return nil
+}
+
+// searchValue searches for a value for path in the map or array v.
+// Returns nil if not found.
+func (entity *Entity) searchValue(v interface{}, path []string) interface{} {
+	switch next := v.(type) {
+	case map[interface{}]interface{}:
+		if len(path) == 1 {
+			return next[path[0]]
+		}
+		return entity.searchValue(next[path[0]], path[1:])
+	case map[string]interface{}:
+		// Type assertion is safe here since it is only reached
+		// if the type of `next` is the same as the type being asserted
+		return entity.searchMap(next, path)
+	case []interface{}:
+		i, ok := index(path[0], len(next))
+		if !ok {
+			return nil
+		}
+		if len(path) == 1 {
+			return next[i]
+		}
+		return entity.searchValue(next[i], path[1:])
+	case []map[string]interface{}:
+		i, ok := index(path[0], len(next))
+		if !ok {
 			return nil
 		}
+		if len(path) == 1 {
+			return next[i]
+		}
+		return entity.searchMap(next[i], path[1:])
+	default:
+		// got a value but nested key expected, return "nil" for not found
+		return nil
 	}
-	return nil
 }
 
 // isPathShadowedInDeepMap makes sure the given path is not shadowed somewhere
@@ -177,6 +575,8 @@ func (entity *Entity) isPathShadowedInDeepMap(path []string, m map[string]interf
 			continue
 		case map[string]interface{}:
 			continue
+		case []interface{}, []map[string]interface{}:
+			continue
 		default:
 			// parentVal is a regular value which shadows "path"
 			return strings.Join(path[0:i], entity.keyDelim)
@@ -185,34 +585,83 @@ func (entity *Entity) isPathShadowedInDeepMap(path []string, m map[string]interf
 	return ""
 }
 
-// find
+// find returns the value for the key from the environment bindings and the
+// data of the Entity, without locking.
 func (entity *Entity) find(key string) interface{} {
-	var (
-		val    interface{}
-		path   = strings.Split(key, entity.keyDelim)
-		nested = len(path) > 1
-	)
-
-	val = entity.searchMap(entity.data, path)
-	if val != nil {
+	key = entity.normalizeKey(key)
+
+	// env override first
+	if val, ok := entity.getEnv(key); ok {
 		return val
 	}
 
-	// compute the path through the nested maps to the nested value
-	if nested && entity.isPathShadowedInDeepMap(path, entity.data) != "" {
+	return entity.searchKey(entity.data, key)
+}
+
+// collect returns every value addressed by key, where a "*" path element
+// matches all elements of an array or all values of a map and a numeric
+// path element indexes into an array.
+func (entity *Entity) collect(key string) []interface{} {
+	delim := entity.keyDelim
+	if delim == "" {
+		delim = ":"
+	}
+	entity.evictExpired()
+	entity.mu.RLock()
+	defer entity.mu.RUnlock()
+
+	return collectPath(entity.data, strings.Split(entity.normalizeKey(key), delim))
+}
+
+func collectPath(source interface{}, path []string) []interface{} {
+	if source == nil {
 		return nil
 	}
+	if len(path) == 0 {
+		return []interface{}{source}
+	}
 
-	return nil
+	var next []interface{}
+	switch v := source.(type) {
+	case map[interface{}]interface{}:
+		return collectPath(cast.ToStringMap(v), path)
+	case map[string]interface{}:
+		if path[0] == "*" {
+			for _, val := range v {
+				next = append(next, val)
+			}
+		} else if val, ok := v[path[0]]; ok {
+			next = append(next, val)
+		}
+	default:
+		s, err := cast.ToSliceE(v)
+		if err != nil {
+			return nil
+		}
+		if path[0] == "*" {
+			next = s
+		} else if i, err := strconv.Atoi(path[0]); err == nil && i >= 0 && i < len(s) {
+			next = append(next, s[i])
+		}
+	}
+
+	var vals []interface{}
+	for _, val := range next {
+		vals = append(vals, collectPath(val, path[1:])...)
+	}
+	return vals
 }
 
 // Get can retrieve any value given the key to use.
 // Get returns an interface. For a specific value use one of the Get____ methods.
 func (entity *Entity) Get(key string) interface{} {
-	val := entity.find(key)
+	val := entity.resolve(key)
 	if val == nil {
 		return nil
 	}
+	if entity.expandEnv {
+		return expandValue(val)
+	}
 	return val
 }
 
@@ -267,8 +716,10 @@ func (entity *Entity) GetTime(key string) time.Time {
 }
 
 // GetDuration returns the value associated with the key as a duration.
+// Strings may use a day unit, e.g. "2d5h".
 func (entity *Entity) GetDuration(key string) time.Duration {
-	return cast.ToDuration(entity.Get(key))
+	d, _ := toDurationE(entity.Get(key))
+	return d
 }
 
 // GetSlice returns the value associated with the key as a slice.
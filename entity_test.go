@@ -8,6 +8,7 @@ package entity
 import (
 	"io/ioutil"
 	"log"
+	"reflect"
 	"testing"
 )
 
@@ -47,3 +48,193 @@ func Test_NewByJson(t *testing.T) {
 		t.Errorf("GetInt 'payload:offsetInMilliseconds' val is not 1023785")
 	}
 }
+
+func TestNew_NormalizesInPlace(t *testing.T) {
+	data := map[string]interface{}{
+		"db":   map[interface{}]interface{}{"host": "localhost"},
+		"list": []interface{}{map[interface{}]interface{}{"port": 1}},
+	}
+
+	e := New(data)
+	if _, ok := data["db"].(map[string]interface{}); !ok {
+		t.Errorf("New should convert the caller's nested map in place, got %T", data["db"])
+	}
+	if _, ok := data["list"].([]interface{})[0].(map[string]interface{}); !ok {
+		t.Error("New should convert the maps in the caller's arrays in place")
+	}
+	data["db"].(map[string]interface{})["host"] = "remote"
+	if e.GetString("db:host") != "remote" {
+		t.Error("New should share data with the caller")
+	}
+
+	data = map[string]interface{}{"DB": map[interface{}]interface{}{"Host": "x"}}
+	if New(data, WithCaseInsensitive()).GetString("db:host") != "x" {
+		t.Error("New with key transforms should normalize a copy")
+	}
+	if _, ok := data["DB"].(map[interface{}]interface{}); !ok {
+		t.Error("New with key transforms should leave the caller's map alone")
+	}
+}
+
+func TestNewFromKV(t *testing.T) {
+	e := NewFromKV("a:b", 1, "a:c", "x", "d", true)
+
+	if e.GetInt("a:b") != 1 || e.GetString("a:c") != "x" || !e.GetBool("d") {
+		t.Errorf("NewFromKV data is %v", e.GetData())
+	}
+
+	e = NewFromKV("a", 1, 2, 3, "b")
+	if len(e.GetData()) != 1 || e.GetInt("a") != 1 {
+		t.Errorf("NewFromKV should skip malformed pairs, data is %v", e.GetData())
+	}
+}
+
+func TestNewFromStringMap(t *testing.T) {
+	e := NewFromStringMap(map[string]string{
+		"replicas": "3",
+		"ratio":    "0.5",
+		"enabled":  "True",
+		"app:name": "web",
+		"version":  "v1",
+		"label":    "NaN",
+		"limit":    "Inf",
+		"max":      "-Infinity",
+		"zip":      "01234",
+		"hex":      "0x1F",
+		"big":      "1e3",
+		"neg":      "-7",
+	})
+
+	if e.Get("replicas") != int64(3) || e.Get("ratio") != 0.5 || e.Get("enabled") != true {
+		t.Errorf("NewFromStringMap parsed data is %v", e.GetData())
+	}
+	if e.GetString("app:name") != "web" || e.Get("version") != "v1" {
+		t.Errorf("NewFromStringMap string data is %v", e.GetData())
+	}
+	for _, key := range []string{"label", "limit", "max", "zip", "hex"} {
+		if _, ok := e.Get(key).(string); !ok {
+			t.Errorf("NewFromStringMap should keep %q as a string, got %v", key, e.Get(key))
+		}
+	}
+	if e.Get("big") != 1000.0 || e.Get("neg") != int64(-7) {
+		t.Errorf("NewFromStringMap numbers are %v and %v", e.Get("big"), e.Get("neg"))
+	}
+	if _, err := e.ToJSON(); err != nil {
+		t.Error("NewFromStringMap data should serialize:", err)
+	}
+}
+
+func TestEntity_IndexPath(t *testing.T) {
+	f, err := ioutil.ReadFile("test_data.json")
+	if err != nil {
+		t.Fatal("read fail", err)
+	}
+	e := NewByJSON(f)
+
+	if e.GetInt("clientContext:1:payload:offsetInMilliseconds") != 1023785 {
+		t.Errorf("GetInt 'clientContext:1:payload:offsetInMilliseconds' val is not 1023785")
+	}
+	if e.Get("clientContext:2:payload") != nil || e.Get("clientContext:x") != nil {
+		t.Error("Get with an invalid index should be nil")
+	}
+
+	e.Set("clientContext:0:payload:token", "t0")
+	if e.GetString("clientContext:0:payload:token") != "t0" {
+		t.Error("Set should overwrite a value inside an array element")
+	}
+
+	e.Set("clientContext:2", map[string]interface{}{"header": map[string]interface{}{"name": "Extra"}})
+	if len(e.GetSlice("clientContext")) != 3 || e.GetString("clientContext:2:header:name") != "Extra" {
+		t.Error("Set at the array length should append an element")
+	}
+
+	if err := e.SetE("clientContext:5", 1); err == nil {
+		t.Error("SetE past the array length should fail")
+	}
+}
+
+func TestWithKeyDelim(t *testing.T) {
+	e := New(map[string]interface{}{
+		"redis": map[string]interface{}{"user:1": "jack"},
+		"urls":  []interface{}{"http://localhost"},
+	}, WithKeyDelim("."))
+
+	if e.GetString("redis.user:1") != "jack" || e.GetString("urls.0") != "http://localhost" {
+		t.Error("Get should split paths on the configured delimiter")
+	}
+
+	e.Set("redis.user:2", "rose")
+	if e.GetString("redis.user:2") != "rose" || !e.Has("redis.user:2") {
+		t.Error("Set should split paths on the configured delimiter")
+	}
+	if _, ok := e.GetData()["redis:user:2"]; ok {
+		t.Error("Set should not split on the default delimiter")
+	}
+
+	if keys := e.AllKeys(); !reflect.DeepEqual(keys, []string{"redis.user:1", "redis.user:2", "urls"}) {
+		t.Errorf("AllKeys is %v", keys)
+	}
+	if e.Clone().GetString("redis.user:1") != "jack" {
+		t.Error("Clone should keep the delimiter")
+	}
+
+	if New(nil, WithKeyDelim("")).keyDelim != ":" {
+		t.Error("an empty delimiter should be ignored")
+	}
+}
+
+func benchmarkEntity(b *testing.B) *Entity {
+	f, err := ioutil.ReadFile("test_data.json")
+	if err != nil {
+		b.Fatal("read fail", err)
+	}
+	return NewByJSON(f)
+}
+
+func BenchmarkEntity_Get(b *testing.B) {
+	e := benchmarkEntity(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.GetString("event:header:name")
+	}
+}
+
+func BenchmarkEntity_GetIndex(b *testing.B) {
+	e := benchmarkEntity(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.GetInt("clientContext:1:payload:offsetInMilliseconds")
+	}
+}
+
+func BenchmarkEntity_GetInterfaceMap(b *testing.B) {
+	e := New(map[string]interface{}{
+		"server": map[interface{}]interface{}{"http": map[interface{}]interface{}{"port": 8080}},
+	})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.GetInt("server:http:port")
+	}
+}
+
+func BenchmarkEntity_GetMissing(b *testing.B) {
+	e := benchmarkEntity(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.Get("event:header:missing")
+	}
+}
+
+func BenchmarkEntity_Set(b *testing.B) {
+	e := benchmarkEntity(b)
+	value := map[string]interface{}{"name": "jack", "tags": []interface{}{"a", "b"}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.Set("event:user", value)
+	}
+}
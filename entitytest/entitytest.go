@@ -0,0 +1,203 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+// Package entitytest provides test assertions for entity.Entity values that
+// report differences by key path.
+//
+// Failure output is colored with ANSI escapes unless the NO_COLOR
+// environment variable is set.
+package entitytest
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/lyf-coder/entity"
+	"github.com/spf13/cast"
+)
+
+// Delim separates the keys of the paths reported in failures.
+var Delim = ":"
+
+const (
+	red   = "\x1b[31m"
+	green = "\x1b[32m"
+	reset = "\x1b[0m"
+)
+
+// AssertEqual fails t if want and got do not hold the same data.
+func AssertEqual(t testing.TB, want, got *entity.Entity) bool {
+	t.Helper()
+	return report(t, "entities differ", diff("", dataOf(want), dataOf(got), false))
+}
+
+// AssertSubset fails t if any value in want is missing from or different in
+// got. Keys only present in got are ignored.
+func AssertSubset(t testing.TB, want, got *entity.Entity) bool {
+	t.Helper()
+	return report(t, "entity is not a superset", diff("", dataOf(want), dataOf(got), true))
+}
+
+// AssertPathEquals fails t if the value for key in e is not want.
+func AssertPathEquals(t testing.TB, e *entity.Entity, key string, want interface{}) bool {
+	t.Helper()
+	return report(t, "value differs", diff(key, want, e.Get(key), false))
+}
+
+// report fails t with lines, if any, and reports whether there were none.
+func report(t testing.TB, msg string, lines []string) bool {
+	t.Helper()
+	if len(lines) == 0 {
+		return true
+	}
+	t.Errorf("%s:\n%s", msg, strings.Join(lines, "\n"))
+	return false
+}
+
+func dataOf(e *entity.Entity) map[string]interface{} {
+	if e == nil {
+		return nil
+	}
+	return e.GetData()
+}
+
+// diff returns a line for each difference between want and got under path.
+// With subset set, values only present in got are not differences.
+func diff(path string, want, got interface{}, subset bool) []string {
+	if wm, ok := asMap(want); ok {
+		if gm, ok := asMap(got); ok {
+			return diffMaps(path, wm, gm, subset)
+		}
+	}
+	if ws, ok := asSlice(want); ok {
+		if gs, ok := asSlice(got); ok {
+			return diffSlices(path, ws, gs, subset)
+		}
+	}
+	if equal(want, got) {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s:\n\t%s\n\t%s", label(path), paint(red, "- "+format(want)), paint(green, "+ "+format(got)))}
+}
+
+func diffMaps(path string, want, got map[string]interface{}, subset bool) []string {
+	keys := make([]string, 0, len(want)+len(got))
+	for k := range want {
+		keys = append(keys, k)
+	}
+	for k := range got {
+		if _, ok := want[k]; !ok && !subset {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var lines []string
+	for _, k := range keys {
+		p := join(path, k)
+		w, inWant := want[k]
+		g, inGot := got[k]
+		switch {
+		case !inGot:
+			lines = append(lines, fmt.Sprintf("%s: %s", label(p), paint(red, "missing, want "+format(w))))
+		case !inWant:
+			lines = append(lines, fmt.Sprintf("%s: %s", label(p), paint(green, "unexpected "+format(g))))
+		default:
+			lines = append(lines, diff(p, w, g, subset)...)
+		}
+	}
+	return lines
+}
+
+func diffSlices(path string, want, got []interface{}, subset bool) []string {
+	var lines []string
+	for i := 0; i < len(want) || i < len(got); i++ {
+		p := join(path, strconv.Itoa(i))
+		switch {
+		case i >= len(got):
+			lines = append(lines, fmt.Sprintf("%s: %s", label(p), paint(red, "missing, want "+format(want[i]))))
+		case i >= len(want):
+			if !subset {
+				lines = append(lines, fmt.Sprintf("%s: %s", label(p), paint(green, "unexpected "+format(got[i]))))
+			}
+		default:
+			lines = append(lines, diff(p, want[i], got[i], subset)...)
+		}
+	}
+	return lines
+}
+
+// equal compares scalars, treating numbers of different types as equal when
+// they have the same value, as JSON decoding turns every number into float64.
+func equal(a, b interface{}) bool {
+	if isNumber(a) && isNumber(b) {
+		return cast.ToFloat64(a) == cast.ToFloat64(b)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func isNumber(v interface{}) bool {
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+func asMap(v interface{}) (map[string]interface{}, bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[interface{}]interface{}:
+		return cast.ToStringMap(v), true
+	default:
+		return nil, false
+	}
+}
+
+func asSlice(v interface{}) ([]interface{}, bool) {
+	switch v.(type) {
+	case []interface{}, []map[string]interface{}:
+		return cast.ToSlice(v), true
+	default:
+		return nil, false
+	}
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + Delim + key
+}
+
+func label(path string) string {
+	if path == "" {
+		return "(root)"
+	}
+	return path
+}
+
+func format(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func paint(color, s string) string {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return s
+	}
+	return color + s + reset
+}
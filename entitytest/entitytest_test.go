@@ -0,0 +1,72 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entitytest
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/lyf-coder/entity"
+)
+
+// recorder is a testing.TB capturing failures instead of reporting them.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestMain(m *testing.M) {
+	os.Setenv("NO_COLOR", "1")
+	os.Exit(m.Run())
+}
+
+func TestAssertEqual(t *testing.T) {
+	want := entity.NewByJSON([]byte(`{"a": {"b": 1, "c": [1, 2]}, "d": "x"}`))
+	got := entity.NewFromKV("a:b", 1, "a:c", []interface{}{1, 2}, "d", "x")
+	if !AssertEqual(t, want, got) {
+		return
+	}
+
+	got.Set("a:c", []interface{}{1, 3}).Set("e", true)
+	r := &recorder{TB: t}
+	if AssertEqual(r, want, got) || len(r.failures) != 1 {
+		t.Fatalf("AssertEqual failures are %v", r.failures)
+	}
+	for _, line := range []string{"a:c:1:\n\t- 2\n\t+ 3", "e: unexpected true"} {
+		if !strings.Contains(r.failures[0], line) {
+			t.Errorf("AssertEqual failure is missing %q:\n%s", line, r.failures[0])
+		}
+	}
+}
+
+func TestAssertSubset(t *testing.T) {
+	want := entity.NewFromKV("a:b", 1)
+	got := entity.NewFromKV("a:b", 1, "a:c", 2)
+	AssertSubset(t, want, got)
+
+	r := &recorder{TB: t}
+	if AssertSubset(r, got, want) || !strings.Contains(r.failures[0], "a:c: missing, want 2") {
+		t.Errorf("AssertSubset failures are %v", r.failures)
+	}
+}
+
+func TestAssertPathEquals(t *testing.T) {
+	e := entity.NewByJSON([]byte(`{"a": {"b": 42}}`))
+	AssertPathEquals(t, e, "a:b", 42)
+
+	r := &recorder{TB: t}
+	if AssertPathEquals(r, e, "a:b", "42") || !strings.Contains(r.failures[0], "a:b:\n\t- \"42\"\n\t+ 42") {
+		t.Errorf("AssertPathEquals failures are %v", r.failures)
+	}
+}
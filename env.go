@@ -0,0 +1,50 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"os"
+)
+
+// envBinding maps a key to the environment variable overriding it.
+type envBinding struct {
+	name      string
+	transform func(string) interface{}
+}
+
+// BindEnv binds key to the environment variable envVar.
+// While envVar is set, Get returns its value for key instead of the stored one.
+// An optional transform converts the raw string before it is returned.
+func (entity *Entity) BindEnv(key, envVar string, transform ...func(string) interface{}) *Entity {
+	entity.mu.Lock()
+	defer entity.mu.Unlock()
+
+	if entity.env == nil {
+		entity.env = make(map[string]envBinding)
+	}
+	binding := envBinding{name: envVar}
+	if len(transform) > 0 {
+		binding.transform = transform[0]
+	}
+	entity.env[entity.normalizeKey(key)] = binding
+	return entity
+}
+
+// getEnv returns the value of the environment variable bound to key.
+func (entity *Entity) getEnv(key string) (interface{}, bool) {
+	binding, ok := entity.env[key]
+	if !ok {
+		return nil, false
+	}
+	val, ok := os.LookupEnv(binding.name)
+	if !ok {
+		return nil, false
+	}
+	if binding.transform != nil {
+		return binding.transform(val), true
+	}
+	return val, true
+}
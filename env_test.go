@@ -0,0 +1,57 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestEntity_BindEnv(t *testing.T) {
+	e := New(map[string]interface{}{"server": map[string]interface{}{"port": 80}})
+	e.BindEnv("server:port", "ENTITY_TEST_PORT")
+	e.BindEnv("server:host", "ENTITY_TEST_HOST", func(s string) interface{} {
+		return strings.ToLower(s)
+	})
+
+	if e.GetInt("server:port") != 80 {
+		t.Error("GetInt 'server:port' should fall back to stored value")
+	}
+
+	os.Setenv("ENTITY_TEST_PORT", "8080")
+	os.Setenv("ENTITY_TEST_HOST", "LOCALHOST")
+	defer os.Unsetenv("ENTITY_TEST_PORT")
+	defer os.Unsetenv("ENTITY_TEST_HOST")
+
+	if e.GetInt("server:port") != 8080 {
+		t.Error("GetInt 'server:port' val is not 8080")
+	}
+	if e.GetString("server:host") != "localhost" {
+		t.Error("GetString 'server:host' val is not localhost")
+	}
+}
+
+func TestEntity_BindEnvConcurrent(t *testing.T) {
+	e := New(map[string]interface{}{"a": 1})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			e.BindEnv("k"+strconv.Itoa(i), "ENTITY_TEST_K"+strconv.Itoa(i))
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			e.Get("k" + strconv.Itoa(i))
+			e.Has("a")
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,308 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+var exampleWords = []string{
+	"alpha", "bravo", "charlie", "delta", "echo", "foxtrot",
+	"golf", "hotel", "india", "juliet", "kilo", "lima",
+}
+
+// GenerateExample returns an Entity holding example data that conforms to
+// the JSON Schema in schema. The same seed yields the same data.
+//
+// const, enum, examples and default are used when present. Strings honor
+// the formats email, uuid, date-time, date, time, uri, hostname and ipv4, as
+// well as minLength and maxLength; numbers honor minimum and maximum; arrays
+// honor minItems and maxItems. Local $ref, allOf, anyOf and oneOf are
+// followed. Every declared property of an object is generated.
+func GenerateExample(schema []byte, seed int64) (*Entity, error) {
+	var root map[string]interface{}
+	if err := json.Unmarshal(schema, &root); err != nil {
+		return nil, err
+	}
+
+	g := &exampleGenerator{root: root, rand: rand.New(rand.NewSource(seed))}
+	v, err := g.value(root, 0)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("schema root generates %T, not an object", v)
+	}
+	return New(m), nil
+}
+
+type exampleGenerator struct {
+	root map[string]interface{}
+	rand *rand.Rand
+}
+
+// maxExampleDepth bounds recursion through self-referencing schemas.
+const maxExampleDepth = 32
+
+func (g *exampleGenerator) value(s map[string]interface{}, depth int) (interface{}, error) {
+	if depth > maxExampleDepth {
+		return nil, fmt.Errorf("schema nesting exceeds %d levels", maxExampleDepth)
+	}
+
+	if ref, ok := s["$ref"].(string); ok {
+		target, err := g.resolve(ref)
+		if err != nil {
+			return nil, err
+		}
+		return g.value(target, depth+1)
+	}
+	if v, ok := s["const"]; ok {
+		return v, nil
+	}
+	if enum, ok := s["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[g.rand.Intn(len(enum))], nil
+	}
+	if examples, ok := s["examples"].([]interface{}); ok && len(examples) > 0 {
+		return examples[g.rand.Intn(len(examples))], nil
+	}
+	if v, ok := s["default"]; ok {
+		return v, nil
+	}
+	if all, ok := s["allOf"].([]interface{}); ok {
+		subs := make([]map[string]interface{}, 0, len(all))
+		for _, sub := range all {
+			m, _ := sub.(map[string]interface{})
+			if ref, ok := m["$ref"].(string); ok {
+				target, err := g.resolve(ref)
+				if err != nil {
+					return nil, err
+				}
+				m = target
+			}
+			subs = append(subs, m)
+		}
+		return g.value(mergeSchemas(s, subs), depth+1)
+	}
+	for _, k := range []string{"oneOf", "anyOf"} {
+		if alts, ok := s[k].([]interface{}); ok && len(alts) > 0 {
+			alt, _ := alts[g.rand.Intn(len(alts))].(map[string]interface{})
+			return g.value(alt, depth+1)
+		}
+	}
+
+	switch schemaType(s) {
+	case "object":
+		return g.object(s, depth)
+	case "array":
+		return g.array(s, depth)
+	case "string":
+		return g.string(s), nil
+	case "integer":
+		min, max := g.bounds(s, 0, 100)
+		lo, n := int64(math.Ceil(min)), int64(math.Floor(max)-math.Ceil(min))+1
+		if n < 1 {
+			return lo, nil
+		}
+		return lo + g.rand.Int63n(n), nil
+	case "number":
+		min, max := g.bounds(s, 0, 100)
+		return math.Round((min+g.rand.Float64()*(max-min))*100) / 100, nil
+	case "boolean":
+		return g.rand.Intn(2) == 1, nil
+	default:
+		return nil, nil
+	}
+}
+
+func (g *exampleGenerator) object(s map[string]interface{}, depth int) (interface{}, error) {
+	props, _ := s["properties"].(map[string]interface{})
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	m := make(map[string]interface{}, len(props))
+	for _, k := range keys {
+		ps, _ := props[k].(map[string]interface{})
+		v, err := g.value(ps, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		m[k] = v
+	}
+	return m, nil
+}
+
+func (g *exampleGenerator) array(s map[string]interface{}, depth int) (interface{}, error) {
+	min, max := 1, 3
+	if v, ok := s["minItems"].(float64); ok {
+		min = int(v)
+		if max < min {
+			max = min
+		}
+	}
+	if v, ok := s["maxItems"].(float64); ok {
+		max = int(v)
+		if min > max {
+			min = max
+		}
+	}
+
+	items, _ := s["items"].(map[string]interface{})
+	n := min + g.rand.Intn(max-min+1)
+	list := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		v, err := g.value(items, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+	}
+	return list, nil
+}
+
+func (g *exampleGenerator) string(s map[string]interface{}) string {
+	word := func() string { return exampleWords[g.rand.Intn(len(exampleWords))] }
+	date := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(g.rand.Int63n(int64(365 * 24 * time.Hour))))
+
+	switch s["format"] {
+	case "email":
+		return word() + "@example.com"
+	case "uuid":
+		b := make([]byte, 16)
+		g.rand.Read(b)
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	case "date-time":
+		return date.Truncate(time.Second).Format(time.RFC3339)
+	case "date":
+		return date.Format("2006-01-02")
+	case "time":
+		return date.Format("15:04:05")
+	case "uri":
+		return "https://example.com/" + word()
+	case "hostname":
+		return word() + ".example.com"
+	case "ipv4":
+		return fmt.Sprintf("192.0.2.%d", 1+g.rand.Intn(254))
+	}
+
+	str := word()
+	if v, ok := s["minLength"].(float64); ok {
+		for len(str) < int(v) {
+			str += "-" + word()
+		}
+	}
+	if v, ok := s["maxLength"].(float64); ok && len(str) > int(v) {
+		str = str[:int(v)]
+	}
+	return str
+}
+
+// bounds returns the numeric range allowed by s, defaulting to [min, max].
+func (g *exampleGenerator) bounds(s map[string]interface{}, min, max float64) (float64, float64) {
+	lo, hasLo := s["minimum"].(float64)
+	hi, hasHi := s["maximum"].(float64)
+	if v, ok := s["exclusiveMinimum"].(float64); ok {
+		lo, hasLo = v+1, true
+	}
+	if v, ok := s["exclusiveMaximum"].(float64); ok {
+		hi, hasHi = v-1, true
+	}
+	switch {
+	case hasLo && hasHi && hi >= lo:
+		return lo, hi
+	case hasLo && hasHi:
+		return lo, lo
+	case hasLo:
+		return lo, lo + (max - min)
+	case hasHi:
+		return hi - (max - min), hi
+	default:
+		return min, max
+	}
+}
+
+// resolve returns the schema a local $ref such as "#/definitions/user" points to.
+func (g *exampleGenerator) resolve(ref string) (map[string]interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported $ref %q", ref)
+	}
+	var cur interface{} = g.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#"), "/") {
+		if part == "" {
+			continue
+		}
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+		part = strings.Replace(strings.Replace(part, "~1", "/", -1), "~0", "~", -1)
+		cur = m[part]
+	}
+	target, ok := cur.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unresolvable $ref %q", ref)
+	}
+	return target, nil
+}
+
+// schemaType returns the type of s, picking the first non-null type of a
+// type list and inferring object or array from their keywords.
+func schemaType(s map[string]interface{}) string {
+	switch t := s["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, v := range t {
+			if v != "null" {
+				str, _ := v.(string)
+				return str
+			}
+		}
+	}
+	if _, ok := s["properties"]; ok {
+		return "object"
+	}
+	if _, ok := s["items"]; ok {
+		return "array"
+	}
+	return ""
+}
+
+// mergeSchemas combines s with the allOf subschemas into a single schema.
+func mergeSchemas(s map[string]interface{}, all []map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{})
+	props := make(map[string]interface{})
+	for _, m := range append([]map[string]interface{}{s}, all...) {
+		for k, v := range m {
+			if k == "allOf" {
+				continue
+			}
+			if k == "properties" {
+				pm, _ := v.(map[string]interface{})
+				for pk, pv := range pm {
+					props[pk] = pv
+				}
+				continue
+			}
+			merged[k] = v
+		}
+	}
+	if len(props) > 0 {
+		merged["properties"] = props
+	}
+	return merged
+}
@@ -0,0 +1,82 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+var userSchema = []byte(`{
+	"$schema": "http://json-schema.org/draft-07/schema#",
+	"type": "object",
+	"definitions": {
+		"address": {"type": "object", "properties": {"city": {"type": "string", "minLength": 12}}}
+	},
+	"properties": {
+		"id": {"type": "string", "format": "uuid"},
+		"email": {"type": "string", "format": "email"},
+		"created": {"type": "string", "format": "date-time"},
+		"age": {"type": "integer", "minimum": 18, "maximum": 20},
+		"role": {"enum": ["admin", "user"]},
+		"active": {"type": "boolean"},
+		"address": {"$ref": "#/definitions/address"},
+		"tags": {"type": "array", "items": {"type": "string", "maxLength": 3}, "minItems": 2, "maxItems": 2},
+		"nickname": {"type": ["null", "string"], "const": "jack"}
+	}
+}`)
+
+func TestGenerateExample(t *testing.T) {
+	e, err := GenerateExample(userSchema, 1)
+	if err != nil {
+		t.Fatal("GenerateExample error:", err)
+	}
+
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(e.GetString("id")) {
+		t.Errorf("id is not a uuid: %q", e.GetString("id"))
+	}
+	if !regexp.MustCompile(`^\w+@example\.com$`).MatchString(e.GetString("email")) {
+		t.Errorf("email is not an email: %q", e.GetString("email"))
+	}
+	if _, err := time.Parse(time.RFC3339, e.GetString("created")); err != nil {
+		t.Errorf("created is not a date-time: %q", e.GetString("created"))
+	}
+	if age := e.GetInt("age"); age < 18 || age > 20 {
+		t.Errorf("age %d is out of range", age)
+	}
+	if role := e.GetString("role"); role != "admin" && role != "user" {
+		t.Errorf("role %q is not in enum", role)
+	}
+	if len(e.GetString("address:city")) < 12 {
+		t.Errorf("address:city %q is too short", e.GetString("address:city"))
+	}
+	if tags := e.GetStringSlice("tags"); len(tags) != 2 || len(tags[0]) > 3 {
+		t.Errorf("tags are %v", tags)
+	}
+	if e.GetString("nickname") != "jack" {
+		t.Errorf("nickname is %q", e.GetString("nickname"))
+	}
+
+	again, _ := GenerateExample(userSchema, 1)
+	if !reflect.DeepEqual(e.GetData(), again.GetData()) {
+		t.Error("GenerateExample should be deterministic for a seed")
+	}
+}
+
+func TestGenerateExample_Errors(t *testing.T) {
+	for _, schema := range []string{
+		`not json`,
+		`{"type": "string"}`,
+		`{"type": "object", "properties": {"a": {"$ref": "#/definitions/missing"}}}`,
+		`{"$ref": "#", "type": "object"}`,
+	} {
+		if _, err := GenerateExample([]byte(schema), 1); err == nil {
+			t.Errorf("GenerateExample(%s) should fail", schema)
+		}
+	}
+}
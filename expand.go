@@ -0,0 +1,140 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// WithEnvExpansion makes Get expand environment variables in string values,
+// see ExpandEnv for the syntax. The stored values are left untouched.
+func WithEnvExpansion() Option {
+	return func(entity *Entity) {
+		entity.expandEnv = true
+	}
+}
+
+// ExpandEnv replaces environment variable references in every string value
+// of the Entity. Both $VAR and ${VAR} are supported, as well as
+// ${VAR:-default}, using default when VAR is unset or empty, and
+// ${VAR-default}, using default only when VAR is unset.
+func (entity *Entity) ExpandEnv() *Entity {
+	entity.evictExpired()
+	entity.mu.Lock()
+	defer entity.unlock()
+
+	var expanded []expansion
+	expanded = collectExpansions(expanded, entity.data, nil)
+	sort.Slice(expanded, func(i, j int) bool {
+		return lessPath(expanded[i].path, expanded[j].path)
+	})
+	for _, x := range expanded {
+		if err := entity.assign(strings.Join(x.path, entity.keyDelim), x.path, x.value); err != nil {
+			log.Println(err)
+		}
+	}
+	return entity
+}
+
+// expansion is a string value changed by ExpandEnv.
+type expansion struct {
+	path  []string
+	value string
+}
+
+// collectExpansions appends the strings in v, at path, that change once
+// environment variables are expanded.
+func collectExpansions(expanded []expansion, v interface{}, path []string) []expansion {
+	switch v := v.(type) {
+	case string:
+		if s := expandEnv(v); s != v {
+			expanded = append(expanded, expansion{path: path, value: s})
+		}
+	case map[string]interface{}:
+		for k, val := range v {
+			expanded = collectExpansions(expanded, val, append(path[:len(path):len(path)], k))
+		}
+	case map[interface{}]interface{}:
+		for k, val := range v {
+			expanded = collectExpansions(expanded, val, append(path[:len(path):len(path)], cast.ToString(k)))
+		}
+	case []interface{}, []map[string]interface{}:
+		for i, val := range cast.ToSlice(v) {
+			expanded = collectExpansions(expanded, val, append(path[:len(path):len(path)], strconv.Itoa(i)))
+		}
+	}
+	return expanded
+}
+
+// lessPath orders paths element by element.
+func lessPath(a, b []string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return len(a) < len(b)
+}
+
+// expandValue returns v with environment variables expanded in all strings,
+// copying maps and slices as needed.
+func expandValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return expandEnv(v)
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[k] = expandValue(val)
+		}
+		return m
+	case map[interface{}]interface{}:
+		m := make(map[interface{}]interface{}, len(v))
+		for k, val := range v {
+			m[k] = expandValue(val)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = expandValue(val)
+		}
+		return s
+	case []map[string]interface{}:
+		s := make([]map[string]interface{}, len(v))
+		for i, val := range v {
+			s[i] = expandValue(val).(map[string]interface{})
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+// expandEnv expands environment variable references in s.
+func expandEnv(s string) string {
+	return os.Expand(s, func(name string) string {
+		if i := strings.Index(name, ":-"); i >= 0 {
+			if val := os.Getenv(name[:i]); val != "" {
+				return val
+			}
+			return name[i+2:]
+		}
+		if i := strings.Index(name, "-"); i >= 0 {
+			if val, ok := os.LookupEnv(name[:i]); ok {
+				return val
+			}
+			return name[i+1:]
+		}
+		return os.Getenv(name)
+	})
+}
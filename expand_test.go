@@ -0,0 +1,77 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestExpandEnv(t *testing.T) {
+	os.Setenv("ENTITY_TEST_HOST", "db.local")
+	os.Setenv("ENTITY_TEST_EMPTY", "")
+	defer os.Unsetenv("ENTITY_TEST_HOST")
+	defer os.Unsetenv("ENTITY_TEST_EMPTY")
+
+	for s, want := range map[string]string{
+		"$ENTITY_TEST_HOST:5432":        "db.local:5432",
+		"${ENTITY_TEST_HOST}":           "db.local",
+		"${ENTITY_TEST_PORT:-8080}":     "8080",
+		"${ENTITY_TEST_EMPTY:-8080}":    "8080",
+		"${ENTITY_TEST_EMPTY-8080}":     "",
+		"${ENTITY_TEST_PORT-8080}":      "8080",
+		"plain":                         "plain",
+		"${ENTITY_TEST_HOST:-ignored}/": "db.local/",
+	} {
+		if got := expandEnv(s); got != want {
+			t.Errorf("expandEnv(%q) is %q, not %q", s, got, want)
+		}
+	}
+}
+
+func TestEntity_ExpandEnv(t *testing.T) {
+	os.Setenv("ENTITY_TEST_HOST", "db.local")
+	defer os.Unsetenv("ENTITY_TEST_HOST")
+	data := map[string]interface{}{
+		"db":    map[string]interface{}{"host": "$ENTITY_TEST_HOST", "port": "${ENTITY_TEST_PORT:-5432}"},
+		"hosts": []interface{}{"${ENTITY_TEST_HOST}"},
+	}
+
+	lazy := New(data, WithEnvExpansion())
+	if lazy.GetString("db:host") != "db.local" || lazy.GetInt("db:port") != 5432 {
+		t.Error("WithEnvExpansion should expand values on read")
+	}
+	if lazy.GetStringSlice("hosts")[0] != "db.local" {
+		t.Error("WithEnvExpansion should expand nested values on read")
+	}
+	if data["db"].(map[string]interface{})["host"] != "$ENTITY_TEST_HOST" {
+		t.Error("WithEnvExpansion should not modify the stored values")
+	}
+
+	e := New(data, WithChangeTracking(), WithModTimes())
+	var keys []string
+	cancel := e.Watch("", func(key string, old, new interface{}) {
+		keys = append(keys, key)
+	})
+	defer cancel()
+	version := e.Version()
+
+	e.ExpandEnv()
+	if e.GetData()["db"].(map[string]interface{})["host"] != "db.local" {
+		t.Error("ExpandEnv should rewrite the stored values")
+	}
+	want := []string{"db:host", "db:port", "hosts:0"}
+	if !reflect.DeepEqual(keys, want) || !reflect.DeepEqual(e.Dirty(), want) {
+		t.Errorf("ExpandEnv changes are %v, dirty %v", keys, e.Dirty())
+	}
+	if c := e.Changes()["db:host"]; c.Old != "$ENTITY_TEST_HOST" || c.New != "db.local" {
+		t.Errorf("ExpandEnv change of 'db:host' is %v", c)
+	}
+	if _, ok := e.ModifiedAt("hosts:0"); !ok || e.Version() != version+3 {
+		t.Error("ExpandEnv should record each rewritten key")
+	}
+}
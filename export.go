@@ -0,0 +1,95 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cast"
+)
+
+// columnsOf returns columns, or the sorted union of the fields of rows if
+// no columns are given.
+func columnsOf(rows []map[string]interface{}, columns []string) []string {
+	if len(columns) > 0 {
+		return columns
+	}
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		for k := range row {
+			if !seen[k] {
+				seen[k] = true
+				columns = append(columns, k)
+			}
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// cellOf renders v as a table cell: scalars as strings, nested values as JSON.
+func cellOf(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return ""
+	case map[interface{}]interface{}:
+		return cellOf(cast.ToStringMap(v))
+	case map[string]interface{}, []interface{}, []map[string]interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return cast.ToString(v)
+		}
+		return string(b)
+	default:
+		return cast.ToString(v)
+	}
+}
+
+// rowsOf returns the header and cells of the object array at key.
+func (entity *Entity) rowsOf(key string, columns []string) [][]string {
+	rows := entity.GetStringMapSlice(key)
+	columns = columnsOf(rows, columns)
+
+	records := [][]string{columns}
+	for _, row := range rows {
+		el := entity.child(row)
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = cellOf(el.Get(column))
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// ToCSV renders the object array at key as CSV with a header row.
+// Without columns, every field found in the elements becomes a column,
+// in sorted order.
+func (entity *Entity) ToCSV(key string, columns ...string) ([]byte, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	if err := w.WriteAll(entity.rowsOf(key, columns)); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// ToTable renders the object array at key as an aligned text table with a
+// header row, for eyeballing payloads in logs. Columns are chosen as in ToCSV.
+func (entity *Entity) ToTable(key string, columns ...string) string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for _, record := range entity.rowsOf(key, columns) {
+		w.Write([]byte(strings.Join(record, "\t") + "\n"))
+	}
+	w.Flush()
+	return b.String()
+}
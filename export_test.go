@@ -0,0 +1,48 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"testing"
+)
+
+var usersJSON = []byte(`{"users": [
+	{"id": 1, "name": "amy", "address": {"city": "Rome"}},
+	{"id": 2, "name": "bob, jr", "tags": ["a", "b"]}
+]}`)
+
+func TestEntity_ToCSV(t *testing.T) {
+	e := NewByJSON(usersJSON)
+
+	b, err := e.ToCSV("users", "id", "name", "address:city")
+	if err != nil {
+		t.Fatal("ToCSV error:", err)
+	}
+	want := "id,name,address:city\n1,amy,Rome\n2,\"bob, jr\",\n"
+	if string(b) != want {
+		t.Errorf("ToCSV is %q, not %q", b, want)
+	}
+
+	b, _ = e.ToCSV("users")
+	want = "address,id,name,tags\n" +
+		"\"{\"\"city\"\":\"\"Rome\"\"}\",1,amy,\n" +
+		",2,\"bob, jr\",\"[\"\"a\"\",\"\"b\"\"]\"\n"
+	if string(b) != want {
+		t.Errorf("ToCSV with detected columns is %q, not %q", b, want)
+	}
+}
+
+func TestEntity_ToTable(t *testing.T) {
+	e := NewByJSON(usersJSON)
+
+	want := "" +
+		"id  name     address:city\n" +
+		"1   amy      Rome\n" +
+		"2   bob, jr  \n"
+	if got := e.ToTable("users", "id", "name", "address:city"); got != want {
+		t.Errorf("ToTable is\n%s\nnot\n%s", got, want)
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// Flatten returns the leaf values of the Entity keyed by their paths joined
+// with the key delimiter, e.g. "users:0:name". Unlike AllKeys, arrays are
+// descended into using element indices. Empty maps and arrays are kept as
+// values so NewFromFlat can restore them.
+//
+// To keep the round trip through NewFromFlat lossless, map keys are escaped
+// with a backslash where they would be ambiguous: a backslash becomes `\\`,
+// the key delimiter becomes `\:` and a key made only of digits, which would
+// read as an array index, gets a leading `\`. So {"a:b": {"0": 1}} flattens
+// to `a\:b:\0`. Such flat keys cannot be passed to Get as is.
+func (entity *Entity) Flatten() map[string]interface{} {
+	data, done := entity.view()
+	defer done()
+
+	flat := make(map[string]interface{})
+	for k, v := range data {
+		entity.flatten(flat, entity.escapeFlatKey(k), v)
+	}
+	return flat
+}
+
+// flatten adds the leaf values of v, keyed under key, to flat.
+func (entity *Entity) flatten(flat map[string]interface{}, key string, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}, map[interface{}]interface{}:
+		m := cast.ToStringMap(v)
+		if len(m) == 0 {
+			flat[key] = map[string]interface{}{}
+		}
+		for k, val := range m {
+			entity.flatten(flat, key+entity.keyDelim+entity.escapeFlatKey(k), val)
+		}
+	case []interface{}, []map[string]interface{}:
+		s := cast.ToSlice(v)
+		if len(s) == 0 {
+			flat[key] = []interface{}{}
+		}
+		for i, val := range s {
+			entity.flatten(flat, key+entity.keyDelim+strconv.Itoa(i), val)
+		}
+	default:
+		flat[key] = v
+	}
+}
+
+// escapeFlatKey escapes the map key k for use as a segment of a flat key.
+func (entity *Entity) escapeFlatKey(k string) string {
+	k = strings.Replace(k, `\`, `\\`, -1)
+	if entity.keyDelim != "" {
+		k = strings.Replace(k, entity.keyDelim, `\`+entity.keyDelim, -1)
+	}
+	if isDigits(k) {
+		k = `\` + k
+	}
+	return k
+}
+
+// flatSegment is a segment of a flat key. Escaped segments are always map
+// keys, never array indices.
+type flatSegment struct {
+	key     string
+	escaped bool
+}
+
+// splitFlatKey splits the flat key on the unescaped key delimiters and
+// unescapes the segments.
+func (entity *Entity) splitFlatKey(key string) []flatSegment {
+	delim := entity.keyDelim
+	var segs []flatSegment
+	var seg flatSegment
+	var b strings.Builder
+	for i := 0; i < len(key); {
+		switch {
+		case key[i] == '\\' && i+1 < len(key):
+			i++
+			switch {
+			case delim != "" && strings.HasPrefix(key[i:], delim):
+				b.WriteString(delim)
+				i += len(delim)
+			case b.Len() == 0 && isDigits(key[i:i+1]):
+				seg.escaped = true
+			default:
+				b.WriteByte(key[i])
+				i++
+			}
+		case delim != "" && strings.HasPrefix(key[i:], delim):
+			seg.key = b.String()
+			segs = append(segs, seg)
+			seg = flatSegment{}
+			b.Reset()
+			i += len(delim)
+		default:
+			b.WriteByte(key[i])
+			i++
+		}
+	}
+	seg.key = b.String()
+	return append(segs, seg)
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits.
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// NewFromFlat returns an initialized Entity instance expanded from a flat map
+// as returned by Flatten, unescaping the keys as described there. Maps whose
+// unescaped keys are exactly 0 to n-1 become arrays; a map with any escaped
+// key stays a map. Options such as WithKeyDelim decide how the keys are split.
+func NewFromFlat(flat map[string]interface{}, opts ...Option) *Entity {
+	entity := New(nil, opts...)
+
+	keys := make([]string, 0, len(flat))
+	for k := range flat {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	root := &flatMap{m: make(map[string]interface{})}
+	for _, k := range keys {
+		segs := entity.splitFlatKey(k)
+		for i := range segs {
+			segs[i].key = entity.normalizeKey(segs[i].key)
+		}
+		entity.insertFlat(root, segs, toCaseInsensitiveValue(flat[k], entity.normalizeKey))
+	}
+	root.keyed = true
+	entity.data = toSlices(root).(map[string]interface{})
+	return entity
+}
+
+// flatMap is a map being built by NewFromFlat. It may become an array unless
+// keyed is set because one of its keys was escaped.
+type flatMap struct {
+	m     map[string]interface{}
+	keyed bool
+}
+
+// insertFlat sets value at segs under node, replacing non-map values on the
+// way, and returns the updated node.
+func (entity *Entity) insertFlat(node interface{}, segs []flatSegment, value interface{}) interface{} {
+	if len(segs) == 0 {
+		return value
+	}
+	n, ok := node.(*flatMap)
+	if !ok {
+		if node != nil {
+			log.Printf("NewFromFlat: key %q: replacing value %v", segs[0].key, node)
+		}
+		n = &flatMap{m: make(map[string]interface{})}
+	}
+	if segs[0].escaped {
+		n.keyed = true
+	}
+	n.m[segs[0].key] = entity.insertFlat(n.m[segs[0].key], segs[1:], value)
+	return n
+}
+
+// toSlices converts the flat maps of v into maps, or into arrays if their
+// keys are unescaped and exactly 0 to n-1.
+func toSlices(v interface{}) interface{} {
+	n, ok := v.(*flatMap)
+	if !ok {
+		return v
+	}
+	m := n.m
+	for k, val := range m {
+		m[k] = toSlices(val)
+	}
+	if n.keyed || len(m) == 0 {
+		return m
+	}
+
+	s := make([]interface{}, len(m))
+	for k, val := range m {
+		i, err := strconv.Atoi(k)
+		if err != nil || i < 0 || i >= len(m) || strconv.Itoa(i) != k {
+			return m
+		}
+		s[i] = val
+	}
+	return s
+}
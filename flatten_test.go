@@ -0,0 +1,87 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestEntity_Flatten(t *testing.T) {
+	e := NewByJSON([]byte(`{"db": {"host": "localhost", "replicas": [{"port": 1}, {"port": 2}]}, "tags": [], "meta": {}, "n": null}`))
+
+	want := map[string]interface{}{
+		"db:host":            "localhost",
+		"db:replicas:0:port": float64(1),
+		"db:replicas:1:port": float64(2),
+		"tags":               []interface{}{},
+		"meta":               map[string]interface{}{},
+		"n":                  nil,
+	}
+	if flat := e.Flatten(); !reflect.DeepEqual(flat, want) {
+		t.Errorf("Flatten is %v", flat)
+	}
+}
+
+func TestNewFromFlat(t *testing.T) {
+	f, err := ioutil.ReadFile("test_data.json")
+	if err != nil {
+		t.Fatal("read fail", err)
+	}
+	e := NewByJSON(f)
+	if got := NewFromFlat(e.Flatten()); !reflect.DeepEqual(got.GetData(), e.GetData()) {
+		t.Errorf("NewFromFlat should restore the flattened data, got %v", got.GetData())
+	}
+
+	flat := map[string]interface{}{"a.0": "x", "a.1": "y", "b.1": 1, "c.d": true}
+	got := NewFromFlat(flat, WithKeyDelim("."))
+	want := map[string]interface{}{
+		"a": []interface{}{"x", "y"},
+		"b": map[string]interface{}{"1": 1},
+		"c": map[string]interface{}{"d": true},
+	}
+	if !reflect.DeepEqual(got.GetData(), want) {
+		t.Errorf("NewFromFlat is %v", got.GetData())
+	}
+	if got.GetString("a.1") != "y" {
+		t.Error("NewFromFlat should keep the key delimiter")
+	}
+}
+
+func TestEntity_FlattenEscaping(t *testing.T) {
+	data := map[string]interface{}{
+		"a:b":   map[string]interface{}{"0": "zero", "1": "one"},
+		`c\d`:   []interface{}{"x", map[string]interface{}{"7": true}},
+		"12":    "top",
+		"empty": map[string]interface{}{},
+	}
+	e := New(data)
+
+	want := map[string]interface{}{
+		`a\:b:\0`:   "zero",
+		`a\:b:\1`:   "one",
+		`c\\d:0`:    "x",
+		`c\\d:1:\7`: true,
+		`\12`:       "top",
+		"empty":     map[string]interface{}{},
+	}
+	flat := e.Flatten()
+	if !reflect.DeepEqual(flat, want) {
+		t.Errorf("Flatten is %v", flat)
+	}
+	if got := NewFromFlat(flat); !reflect.DeepEqual(got.GetData(), e.GetData()) {
+		t.Errorf("NewFromFlat should restore keys with delimiters and digits, got %v", got.GetData())
+	}
+
+	dotted := NewFromFlat(map[string]interface{}{`a\.b.0`: 1, `c.\0`: 2}, WithKeyDelim("."))
+	if !reflect.DeepEqual(dotted.GetData(), map[string]interface{}{
+		"a.b": []interface{}{1},
+		"c":   map[string]interface{}{"0": 2},
+	}) {
+		t.Errorf("NewFromFlat with a custom delimiter is %v", dotted.GetData())
+	}
+}
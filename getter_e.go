@@ -0,0 +1,190 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+var (
+	// ErrKeyNotFound is returned by the GetXE methods when the key is missing.
+	ErrKeyNotFound = errors.New("key not found")
+	// ErrInvalidType is returned by the GetXE methods when the value cannot
+	// be converted to the requested type.
+	ErrInvalidType = errors.New("invalid type")
+)
+
+// NewByJSONE returns an initialized Entity instance by json byte[],
+// or the error that prevented parsing it.
+func NewByJSONE(data []byte) (*Entity, error) {
+	return NewByFormat("json", data)
+}
+
+// NewByYAMLE returns an initialized Entity instance by yaml byte[],
+// or the error that prevented parsing it.
+func NewByYAMLE(data []byte) (*Entity, error) {
+	return NewByFormat("yaml", data)
+}
+
+// NewByTOMLE returns an initialized Entity instance by toml byte[],
+// or the error that prevented parsing it.
+func NewByTOMLE(data []byte) (*Entity, error) {
+	return NewByFormat("toml", data)
+}
+
+// GetE returns the value for the key, or an error wrapping ErrKeyNotFound.
+func (entity *Entity) GetE(key string) (interface{}, error) {
+	val := entity.Get(key)
+	if val == nil {
+		return nil, fmt.Errorf("key %q: %w", key, ErrKeyNotFound)
+	}
+	return val, nil
+}
+
+// getE returns the value for the key converted by conv, or an error wrapping
+// ErrKeyNotFound or ErrInvalidType.
+func (entity *Entity) getE(key string, conv func(interface{}) (interface{}, error)) (interface{}, error) {
+	val, err := entity.GetE(key)
+	if err != nil {
+		return nil, err
+	}
+	v, err := conv(val)
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w (%v)", key, ErrInvalidType, err)
+	}
+	return v, nil
+}
+
+// GetStringE returns the value associated with the key as a string.
+func (entity *Entity) GetStringE(key string) (string, error) {
+	v, err := entity.getE(key, func(i interface{}) (interface{}, error) { return cast.ToStringE(i) })
+	s, _ := v.(string)
+	return s, err
+}
+
+// GetBoolE returns the value associated with the key as a boolean.
+func (entity *Entity) GetBoolE(key string) (bool, error) {
+	v, err := entity.getE(key, func(i interface{}) (interface{}, error) { return cast.ToBoolE(i) })
+	b, _ := v.(bool)
+	return b, err
+}
+
+// GetIntE returns the value associated with the key as an integer.
+func (entity *Entity) GetIntE(key string) (int, error) {
+	v, err := entity.getE(key, func(i interface{}) (interface{}, error) { return cast.ToIntE(i) })
+	n, _ := v.(int)
+	return n, err
+}
+
+// GetInt32E returns the value associated with the key as an integer.
+func (entity *Entity) GetInt32E(key string) (int32, error) {
+	v, err := entity.getE(key, func(i interface{}) (interface{}, error) { return cast.ToInt32E(i) })
+	n, _ := v.(int32)
+	return n, err
+}
+
+// GetInt64E returns the value associated with the key as an integer.
+func (entity *Entity) GetInt64E(key string) (int64, error) {
+	v, err := entity.getE(key, func(i interface{}) (interface{}, error) { return cast.ToInt64E(i) })
+	n, _ := v.(int64)
+	return n, err
+}
+
+// GetUintE returns the value associated with the key as an unsigned integer.
+func (entity *Entity) GetUintE(key string) (uint, error) {
+	v, err := entity.getE(key, func(i interface{}) (interface{}, error) { return cast.ToUintE(i) })
+	n, _ := v.(uint)
+	return n, err
+}
+
+// GetUint32E returns the value associated with the key as an unsigned integer.
+func (entity *Entity) GetUint32E(key string) (uint32, error) {
+	v, err := entity.getE(key, func(i interface{}) (interface{}, error) { return cast.ToUint32E(i) })
+	n, _ := v.(uint32)
+	return n, err
+}
+
+// GetUint64E returns the value associated with the key as an unsigned integer.
+func (entity *Entity) GetUint64E(key string) (uint64, error) {
+	v, err := entity.getE(key, func(i interface{}) (interface{}, error) { return cast.ToUint64E(i) })
+	n, _ := v.(uint64)
+	return n, err
+}
+
+// GetFloat64E returns the value associated with the key as a float64.
+func (entity *Entity) GetFloat64E(key string) (float64, error) {
+	v, err := entity.getE(key, func(i interface{}) (interface{}, error) { return cast.ToFloat64E(i) })
+	f, _ := v.(float64)
+	return f, err
+}
+
+// GetTimeE returns the value associated with the key as time.
+func (entity *Entity) GetTimeE(key string) (time.Time, error) {
+	v, err := entity.getE(key, func(i interface{}) (interface{}, error) { return cast.ToTimeE(i) })
+	t, _ := v.(time.Time)
+	return t, err
+}
+
+// GetDurationE returns the value associated with the key as a duration.
+// Strings may use a day unit, e.g. "2d5h".
+func (entity *Entity) GetDurationE(key string) (time.Duration, error) {
+	v, err := entity.getE(key, func(i interface{}) (interface{}, error) { return toDurationE(i) })
+	d, _ := v.(time.Duration)
+	return d, err
+}
+
+// GetSliceE returns the value associated with the key as a slice.
+func (entity *Entity) GetSliceE(key string) ([]interface{}, error) {
+	v, err := entity.getE(key, func(i interface{}) (interface{}, error) { return cast.ToSliceE(i) })
+	s, _ := v.([]interface{})
+	return s, err
+}
+
+// GetStringMapSliceE returns the value associated with the key as a []map[string]interface{} slice.
+func (entity *Entity) GetStringMapSliceE(key string) ([]map[string]interface{}, error) {
+	v, err := entity.getE(key, func(i interface{}) (interface{}, error) { return ToStringMapSlice(i) })
+	s, _ := v.([]map[string]interface{})
+	return s, err
+}
+
+// GetIntSliceE returns the value associated with the key as a slice of int values.
+func (entity *Entity) GetIntSliceE(key string) ([]int, error) {
+	v, err := entity.getE(key, func(i interface{}) (interface{}, error) { return cast.ToIntSliceE(i) })
+	s, _ := v.([]int)
+	return s, err
+}
+
+// GetStringSliceE returns the value associated with the key as a slice of strings.
+func (entity *Entity) GetStringSliceE(key string) ([]string, error) {
+	v, err := entity.getE(key, func(i interface{}) (interface{}, error) { return cast.ToStringSliceE(i) })
+	s, _ := v.([]string)
+	return s, err
+}
+
+// GetStringMapE returns the value associated with the key as a map of interfaces.
+func (entity *Entity) GetStringMapE(key string) (map[string]interface{}, error) {
+	v, err := entity.getE(key, func(i interface{}) (interface{}, error) { return cast.ToStringMapE(i) })
+	m, _ := v.(map[string]interface{})
+	return m, err
+}
+
+// GetStringMapStringE returns the value associated with the key as a map of strings.
+func (entity *Entity) GetStringMapStringE(key string) (map[string]string, error) {
+	v, err := entity.getE(key, func(i interface{}) (interface{}, error) { return cast.ToStringMapStringE(i) })
+	m, _ := v.(map[string]string)
+	return m, err
+}
+
+// GetStringMapStringSliceE returns the value associated with the key as a map to a slice of strings.
+func (entity *Entity) GetStringMapStringSliceE(key string) (map[string][]string, error) {
+	v, err := entity.getE(key, func(i interface{}) (interface{}, error) { return cast.ToStringMapStringSliceE(i) })
+	m, _ := v.(map[string][]string)
+	return m, err
+}
@@ -0,0 +1,49 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewByJSONE(t *testing.T) {
+	if _, err := NewByJSONE([]byte(`{"a":`)); err == nil {
+		t.Error("NewByJSONE of invalid json should fail")
+	}
+	e, err := NewByJSONE([]byte(`{"a": 1}`))
+	if err != nil || e.GetInt("a") != 1 {
+		t.Errorf("NewByJSONE is %v, %v", e, err)
+	}
+}
+
+func TestEntity_GetIntE(t *testing.T) {
+	e := NewByJSON([]byte(`{"zero": 0, "name": "jack", "n": "42"}`))
+
+	if n, err := e.GetIntE("zero"); n != 0 || err != nil {
+		t.Errorf("GetIntE of a stored zero is %d, %v", n, err)
+	}
+	if n, err := e.GetIntE("n"); n != 42 || err != nil {
+		t.Errorf("GetIntE of a numeric string is %d, %v", n, err)
+	}
+	if _, err := e.GetIntE("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("GetIntE of a missing key error is %v", err)
+	}
+	if _, err := e.GetIntE("name"); !errors.Is(err, ErrInvalidType) {
+		t.Errorf("GetIntE of a string error is %v", err)
+	}
+}
+
+func TestEntity_GetStringMapE(t *testing.T) {
+	e := NewByJSON([]byte(`{"a": {"b": 1}, "c": [1]}`))
+
+	if m, err := e.GetStringMapE("a"); err != nil || m["b"] != float64(1) {
+		t.Errorf("GetStringMapE is %v, %v", m, err)
+	}
+	if _, err := e.GetStringMapE("c"); !errors.Is(err, ErrInvalidType) {
+		t.Errorf("GetStringMapE of an array error is %v", err)
+	}
+}
@@ -2,4 +2,10 @@ module github.com/lyf-coder/entity
 
 go 1.13
 
-require github.com/spf13/cast v1.3.1
+require (
+	github.com/BurntSushi/toml v0.3.1
+	github.com/mitchellh/mapstructure v1.1.2
+	github.com/spf13/cast v1.3.1
+	golang.org/x/text v0.3.6
+	gopkg.in/yaml.v2 v2.2.8
+)
@@ -0,0 +1,72 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// ToJSON serializes the Entity data as JSON.
+func (entity *Entity) ToJSON() ([]byte, error) {
+	return entity.Encode("json")
+}
+
+// ToJSONIndent serializes the Entity data as JSON like json.MarshalIndent.
+func (entity *Entity) ToJSONIndent(prefix, indent string) ([]byte, error) {
+	if order := entity.orderSnapshot(); order != nil {
+		b, err := entity.ToJSON()
+		if err != nil {
+			return nil, err
+		}
+		var out bytes.Buffer
+		err = json.Indent(&out, b, prefix, indent)
+		return out.Bytes(), err
+	}
+
+	data, done := entity.view()
+	defer done()
+
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	return json.MarshalIndent(data, prefix, indent)
+}
+
+// MarshalJSON implements json.Marshaler, so an Entity can be embedded in
+// values encoded with encoding/json.
+func (entity *Entity) MarshalJSON() ([]byte, error) {
+	return entity.ToJSON()
+}
+
+// UnmarshalJSON implements json.Unmarshaler, replacing the Entity data with
+// the decoded JSON object. The settings of the Entity are kept, and each
+// top-level key that changes is reported like a Set.
+func (entity *Entity) UnmarshalJSON(data []byte) error {
+	m, err := jsonCodec{}.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	entity.evictExpired()
+	entity.mu.Lock()
+	defer entity.unlock()
+
+	if entity.keyDelim == "" {
+		entity.keyDelim = ":"
+	}
+	if len(entity.keyTransforms) > 0 {
+		m = copyAndInsensitiveMap(m, entity.normalizeKey)
+	}
+	if entity.order != nil {
+		entity.order = newKeyOrder()
+		if err := entity.order.readJSON(data, entity.normalizeKey); err != nil {
+			return err
+		}
+	}
+	entity.replaceData(m)
+	return nil
+}
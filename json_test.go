@@ -0,0 +1,85 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEntity_ToJSON(t *testing.T) {
+	e := New(nil)
+	e.Set("a:b", []interface{}{1, map[string]interface{}{"c": true}})
+
+	b, err := e.ToJSON()
+	if err != nil {
+		t.Fatal("ToJSON error:", err)
+	}
+	if string(b) != `{"a":{"b":[1,{"c":true}]}}` {
+		t.Errorf("ToJSON is %s", b)
+	}
+
+	b, err = e.ToJSONIndent("", "  ")
+	if err != nil {
+		t.Fatal("ToJSONIndent error:", err)
+	}
+	if want := "{\n  \"a\": {\n    \"b\": [\n      1,\n      {\n        \"c\": true\n      }\n    ]\n  }\n}"; string(b) != want {
+		t.Errorf("ToJSONIndent is %s", b)
+	}
+}
+
+func TestEntity_JSONRoundTrip(t *testing.T) {
+	type message struct {
+		ID      int     `json:"id"`
+		Payload *Entity `json:"payload"`
+	}
+	in := message{ID: 1, Payload: NewByJSON([]byte(`{"user": {"name": "jack", "tags": ["a", "b"]}}`))}
+
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal("json.Marshal error:", err)
+	}
+	var out message
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal("json.Unmarshal error:", err)
+	}
+	if out.ID != 1 || out.Payload.GetString("user:name") != "jack" || out.Payload.GetString("user:tags:1") != "b" {
+		t.Errorf("round trip payload is %v", out.Payload.GetData())
+	}
+	if !reflect.DeepEqual(in.Payload.GetData(), out.Payload.GetData()) {
+		t.Error("round trip should preserve the data")
+	}
+
+	if err := out.Payload.UnmarshalJSON([]byte(`[1]`)); err == nil {
+		t.Error("UnmarshalJSON of an array should fail")
+	}
+}
+
+func TestEntity_UnmarshalJSONNotifies(t *testing.T) {
+	e := New(nil, WithChangeTracking())
+	e.Set("a", 1.0).Set("b", 2.0)
+	e.SetWithTTL("c", 3, time.Hour)
+	e.ResetDirty()
+
+	var keys []string
+	cancel := e.Watch("", func(key string, old, new interface{}) {
+		keys = append(keys, key)
+	})
+	defer cancel()
+
+	if err := e.UnmarshalJSON([]byte(`{"a": 1, "b": 5, "d": 4}`)); err != nil {
+		t.Fatal("UnmarshalJSON error:", err)
+	}
+	want := []string{"b", "c", "d"}
+	if !reflect.DeepEqual(keys, want) || !reflect.DeepEqual(e.Dirty(), want) {
+		t.Errorf("UnmarshalJSON changes are %v, dirty %v", keys, e.Dirty())
+	}
+	if len(e.expires) != 0 {
+		t.Error("UnmarshalJSON should clear the TTL of removed keys")
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// Delete removes the value for the key, which may address an array element,
+// and reports whether there was one.
+func (entity *Entity) Delete(key string) bool {
+	return entity.delete(key, false)
+}
+
+// DeletePrune removes the value for the key like Delete, and also the maps
+// left empty by the removal, up to the root.
+func (entity *Entity) DeletePrune(key string) bool {
+	return entity.delete(key, true)
+}
+
+// delete removes the value for the key, pruning empty maps if prune is set.
+func (entity *Entity) delete(key string, prune bool) bool {
+	entity.evictExpired()
+	entity.mu.Lock()
+	defer entity.unlock()
+
+	entity.clearTTL(key)
+	return entity.remove(key, prune)
+}
+
+// remove deletes the value for the key without locking.
+func (entity *Entity) remove(key string, prune bool) bool {
+	key = entity.normalizeKey(key)
+	return entity.unset(strings.Split(key, entity.keyDelim), prune)
+}
+
+// unset deletes the value at the normalized path without locking.
+func (entity *Entity) unset(path []string, prune bool) bool {
+	key := strings.Join(path, entity.keyDelim)
+	old, ok := entity.lookupPath(path)
+	if !ok {
+		return false
+	}
+
+	entity.track(key, path)
+	for i := len(path) - 1; i >= 0; i-- {
+		parent := entity.searchMap(entity.data, path[:i])
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			delete(p, path[i])
+		case map[interface{}]interface{}:
+			delete(p, path[i])
+		case []interface{}, []map[string]interface{}:
+			s := cast.ToSlice(p)
+			idx, _ := strconv.Atoi(path[i])
+			s = append(s[:idx:idx], s[idx+1:]...)
+			if _, err := setPath(entity.data, path[:i], s); err != nil {
+				return false
+			}
+		}
+
+		entity.order.remove(path[:i+1])
+		if !prune || i == 0 {
+			break
+		}
+		if m, ok := entity.searchMap(entity.data, path[:i]).(map[string]interface{}); !ok || len(m) > 0 {
+			break
+		}
+	}
+
+	entity.touch(key)
+	entity.notify(key, old, nil)
+	return true
+}
+
+// Has reports whether the key is set, even if its value is nil or zero, or
+// has a default or fallback value.
+func (entity *Entity) Has(key string) bool {
+	entity.evictExpired()
+	entity.mu.RLock()
+	key = entity.normalizeKey(key)
+	_, ok := entity.getEnv(key)
+	path := strings.Split(key, entity.keyDelim)
+	if !ok {
+		_, ok = entity.lookupPath(path)
+	}
+	entity.mu.RUnlock()
+
+	return ok || entity.resolveDefault(path) != nil
+}
+
+// IsSet is an alias of Has.
+func (entity *Entity) IsSet(key string) bool {
+	return entity.Has(key)
+}
+
+// lookupPath returns the value at path and whether it exists.
+func (entity *Entity) lookupPath(path []string) (interface{}, bool) {
+	var cur interface{} = entity.data
+	for _, k := range path {
+		switch c := cur.(type) {
+		case map[string]interface{}:
+			v, ok := c[k]
+			if !ok {
+				return nil, false
+			}
+			cur = v
+		case map[interface{}]interface{}:
+			v, ok := c[k]
+			if !ok {
+				return nil, false
+			}
+			cur = v
+		case []interface{}, []map[string]interface{}:
+			s := cast.ToSlice(c)
+			i, err := strconv.Atoi(k)
+			if err != nil || i < 0 || i >= len(s) {
+				return nil, false
+			}
+			cur = s[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// Keys returns the top-level keys of the Entity, sorted.
+func (entity *Entity) Keys() []string {
+	data, done := entity.view()
+	defer done()
+
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// AllKeys returns the paths of all leaf values of the Entity, joined with
+// the key delimiter and sorted. Nested maps are descended into; arrays are
+// leaves.
+func (entity *Entity) AllKeys() []string {
+	data, done := entity.view()
+	defer done()
+
+	keys := entity.flattenKeys(nil, data, "")
+	sort.Strings(keys)
+	return keys
+}
+
+// flattenKeys appends the leaf paths of m, prefixed with prefix, to keys.
+func (entity *Entity) flattenKeys(keys []string, m map[string]interface{}, prefix string) []string {
+	for k, v := range m {
+		key := prefix + k
+		switch v := v.(type) {
+		case map[string]interface{}:
+			keys = entity.flattenKeys(keys, v, key+entity.keyDelim)
+		case map[interface{}]interface{}:
+			keys = entity.flattenKeys(keys, cast.ToStringMap(v), key+entity.keyDelim)
+		default:
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
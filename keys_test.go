@@ -0,0 +1,57 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEntity_Delete(t *testing.T) {
+	e := NewByJSON([]byte(`{"a": {"b": {"c": 1}, "d": 2}, "list": [1, 2, 3], "x": {"y": {"z": 1}}}`))
+
+	if !e.Delete("a:b:c") || e.Has("a:b:c") || !e.Has("a:b") {
+		t.Error("Delete should keep the empty parent")
+	}
+	if e.Delete("a:b:c") {
+		t.Error("Delete of a missing key should report false")
+	}
+	if !e.DeletePrune("x:y:z") || e.Has("x") {
+		t.Errorf("DeletePrune should remove empty parents, data is %v", e.GetData())
+	}
+	if !e.Delete("list:1") || !reflect.DeepEqual(e.GetIntSlice("list"), []int{1, 3}) {
+		t.Errorf("Delete of an array element should shift the rest, list is %v", e.Get("list"))
+	}
+}
+
+func TestEntity_Has(t *testing.T) {
+	e := NewByJSON([]byte(`{"a": null, "b": 0, "c": {"d": false}, "list": [null]}`))
+
+	for key, want := range map[string]bool{
+		"a":       true,
+		"b":       true,
+		"c:d":     true,
+		"list:0":  true,
+		"list:1":  false,
+		"missing": false,
+		"b:x":     false,
+	} {
+		if got := e.Has(key); got != want {
+			t.Errorf("Has(%q) is %v, not %v", key, got, want)
+		}
+	}
+}
+
+func TestEntity_AllKeys(t *testing.T) {
+	e := NewByJSON([]byte(`{"b": {"y": 1, "x": {"z": 2}}, "a": [1], "c": null}`))
+
+	if got := e.Keys(); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("Keys is %v", got)
+	}
+	if got := e.AllKeys(); !reflect.DeepEqual(got, []string{"a", "b:x:z", "b:y", "c"}) {
+		t.Errorf("AllKeys is %v", got)
+	}
+}
@@ -0,0 +1,197 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// Merge deep merges the data of other into the Entity: nested maps are
+// merged key by key, while scalars and arrays from other replace existing
+// values. The merged values are copied, so later changes to either Entity
+// do not affect the other.
+func (entity *Entity) Merge(other *Entity) error {
+	if other == nil {
+		return nil
+	}
+	return entity.MergeMap(other.snapshot())
+}
+
+// MergeMap deep merges m into the Entity like Merge does.
+// It returns ErrCircularReference if m contains itself.
+func (entity *Entity) MergeMap(m map[string]interface{}) error {
+	return entity.merge(m, false)
+}
+
+// ApplyMergePatch applies the JSON merge patch (RFC 7386) m, as returned by
+// Diff, to the Entity. It merges like MergeMap, except that keys whose
+// value in m is nil are deleted.
+func (entity *Entity) ApplyMergePatch(m map[string]interface{}) error {
+	return entity.merge(m, true)
+}
+
+// merge deep merges m into the Entity, deleting keys set to nil in m if
+// patch is set.
+func (entity *Entity) merge(m map[string]interface{}, patch bool) error {
+	if hasCycle(m, make(map[container]bool)) {
+		return ErrCircularReference
+	}
+
+	entity.evictExpired()
+	entity.mu.Lock()
+	defer entity.unlock()
+
+	if entity.data == nil {
+		entity.data = make(map[string]interface{})
+	}
+	if entity.keyDelim == "" {
+		entity.keyDelim = ":"
+	}
+	src := copyAndInsensitiveMap(m, entity.normalizeKey)
+	entity.mergeMaps(entity.data, deepCopy(src).(map[string]interface{}), nil, patch)
+	return nil
+}
+
+// mergeMaps merges src into dst, the map at prefix, recording each modified
+// key. If patch is set, keys set to nil in src are deleted from dst.
+func (entity *Entity) mergeMaps(dst, src map[string]interface{}, prefix []string, patch bool) {
+	for k, v := range src {
+		path := append(prefix[:len(prefix):len(prefix)], k)
+		key := strings.Join(path, entity.keyDelim)
+		sm, ok := v.(map[string]interface{})
+		if ok {
+			if dm, ok := dst[k].(map[string]interface{}); ok {
+				entity.mergeMaps(dm, sm, path, patch)
+				continue
+			}
+		}
+		if patch && v == nil {
+			if old, ok := dst[k]; ok {
+				entity.clearTTL(key)
+				entity.track(key, path)
+				delete(dst, k)
+				entity.order.remove(path)
+				entity.touch(key)
+				entity.notify(key, old, nil)
+			}
+			continue
+		}
+		if patch && ok {
+			v = withoutNils(sm)
+		}
+		entity.clearTTL(key)
+		entity.track(key, path)
+		old := dst[k]
+		dst[k] = v
+		entity.order.add(path)
+		entity.touch(key)
+		entity.notify(key, old, v)
+	}
+}
+
+// withoutNils removes the keys set to nil from m and its nested maps, as a
+// merge patch does when it adds a new object.
+func withoutNils(m map[string]interface{}) map[string]interface{} {
+	for k, v := range m {
+		switch v := v.(type) {
+		case nil:
+			delete(m, k)
+		case map[string]interface{}:
+			withoutNils(v)
+		}
+	}
+	return m
+}
+
+// Clone returns a deep copy of the Entity, including its settings,
+// defaults, fallback, environment bindings, TTLs, modification times and
+// tracked changes.
+func (entity *Entity) Clone() *Entity {
+	entity.evictExpired()
+	entity.mu.RLock()
+	defer entity.mu.RUnlock()
+
+	var data map[string]interface{}
+	if entity.data != nil {
+		data = deepCopy(entity.data).(map[string]interface{})
+	}
+	c := entity.child(data)
+	c.version = entity.version
+	c.fallback = entity.fallback
+	c.order = entity.order.clone()
+	c.comments = copyComments(entity.comments)
+	if entity.defaults != nil {
+		c.defaults = deepCopy(entity.defaults).(map[string]interface{})
+	}
+	if entity.env != nil {
+		c.env = make(map[string]envBinding, len(entity.env))
+		for k, v := range entity.env {
+			c.env[k] = v
+		}
+	}
+	if entity.expires != nil {
+		c.expires = make(map[string]time.Time, len(entity.expires))
+		for k, v := range entity.expires {
+			c.expires[k] = v
+		}
+	}
+	if entity.changes != nil {
+		c.changes = make(map[string]tracked, len(entity.changes))
+		for k, t := range entity.changes {
+			c.changes[k] = tracked{path: t.path, old: deepCopy(t.old)}
+		}
+	}
+	if entity.modTimes != nil {
+		c.modTimes = make(map[string]time.Time, len(entity.modTimes))
+		for k, v := range entity.modTimes {
+			c.modTimes[k] = v
+		}
+	}
+	return c
+}
+
+// snapshot returns a deep copy of the data of the Entity.
+func (entity *Entity) snapshot() map[string]interface{} {
+	entity.evictExpired()
+	entity.mu.RLock()
+	defer entity.mu.RUnlock()
+
+	m, _ := deepCopy(entity.data).(map[string]interface{})
+	return m
+}
+
+// deepCopy returns a copy of v sharing no maps or arrays with it.
+func deepCopy(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[k] = deepCopy(val)
+		}
+		return m
+	case map[interface{}]interface{}:
+		m := make(map[interface{}]interface{}, len(v))
+		for k, val := range v {
+			m[k] = deepCopy(val)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = deepCopy(val)
+		}
+		return s
+	case []map[string]interface{}:
+		s := make([]map[string]interface{}, len(v))
+		for i, val := range v {
+			s[i] = deepCopy(val).(map[string]interface{})
+		}
+		return s
+	default:
+		return v
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEntity_Merge(t *testing.T) {
+	base := NewByJSON([]byte(`{"db": {"host": "localhost", "port": 5432}, "tags": ["a", "b"], "debug": false}`))
+	override := NewByJSON([]byte(`{"db": {"host": "db.prod", "pool": {"size": 10}}, "tags": ["c"], "debug": true}`))
+
+	if err := base.Merge(override); err != nil {
+		t.Fatal("Merge error:", err)
+	}
+	want := map[string]interface{}{
+		"db":    map[string]interface{}{"host": "db.prod", "port": float64(5432), "pool": map[string]interface{}{"size": float64(10)}},
+		"tags":  []interface{}{"c"},
+		"debug": true,
+	}
+	if !reflect.DeepEqual(base.GetData(), want) {
+		t.Errorf("Merge result is %v", base.GetData())
+	}
+
+	base.Set("db:pool:size", 20)
+	base.GetSlice("tags")[0] = "changed"
+	if override.GetInt("db:pool:size") != 10 || override.GetString("tags:0") != "c" {
+		t.Error("changes to the merged entity should not leak into the source")
+	}
+}
+
+func TestEntity_MergeMap(t *testing.T) {
+	e := NewFromKV("a:b", 1)
+	loop := map[string]interface{}{}
+	loop["self"] = []interface{}{loop}
+
+	if err := e.MergeMap(loop); err != ErrCircularReference {
+		t.Errorf("MergeMap of a circular map error is %v", err)
+	}
+	if err := e.MergeMap(map[string]interface{}{"a": map[string]interface{}{"c": 2}}); err != nil {
+		t.Fatal("MergeMap error:", err)
+	}
+	if e.GetInt("a:b") != 1 || e.GetInt("a:c") != 2 {
+		t.Errorf("MergeMap result is %v", e.GetData())
+	}
+}
+
+func TestEntity_Clone(t *testing.T) {
+	e := NewByJSON([]byte(`{"a": {"list": [{"x": 1}]}}`))
+	c := e.Clone()
+
+	c.Set("a:list:0:x", 2)
+	c.Set("b", true)
+	if e.GetInt("a:list:0:x") != 1 || e.Has("b") {
+		t.Errorf("changes to the clone should not affect the original, data is %v", e.GetData())
+	}
+	if !reflect.DeepEqual(NewByJSON([]byte(`{"a": {"list": [{"x": 1}]}}`)).GetData(), e.Clone().GetData()) {
+		t.Error("Clone should copy the data")
+	}
+}
+
+func TestEntity_ApplyMergePatch(t *testing.T) {
+	e := NewByJSON([]byte(`{"a": {"b": 1, "c": 2}, "d": true}`))
+	patch := map[string]interface{}{
+		"a": map[string]interface{}{"b": nil},
+		"d": nil,
+		"e": map[string]interface{}{"f": 3, "g": nil},
+		"x": nil,
+	}
+	if err := e.ApplyMergePatch(patch); err != nil {
+		t.Fatal("ApplyMergePatch error:", err)
+	}
+	want := map[string]interface{}{
+		"a": map[string]interface{}{"c": float64(2)},
+		"e": map[string]interface{}{"f": 3},
+	}
+	if !reflect.DeepEqual(e.GetData(), want) {
+		t.Errorf("ApplyMergePatch result is %v", e.GetData())
+	}
+}
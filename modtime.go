@@ -0,0 +1,50 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// WithModTimes makes the Entity record when each key is modified,
+// see ModifiedAt.
+func WithModTimes() Option {
+	return func(entity *Entity) {
+		entity.modTimes = make(map[string]time.Time)
+	}
+}
+
+// touch records a modification of key.
+func (entity *Entity) touch(key string) {
+	entity.version++
+	if entity.modTimes != nil {
+		entity.modTimes[key] = time.Now()
+	}
+}
+
+// ModifiedAt returns when the value for the key was last modified, either
+// directly or by modifying a key nested under it or a key containing it.
+// ok is false if the key has not been modified since the Entity was created
+// or if modification times are not recorded, see WithModTimes.
+func (entity *Entity) ModifiedAt(key string) (t time.Time, ok bool) {
+	entity.mu.RLock()
+	defer entity.mu.RUnlock()
+
+	key = entity.normalizeKey(key)
+	for k, mt := range entity.modTimes {
+		if isRelatedKey(k, key, entity.keyDelim) && mt.After(t) {
+			t, ok = mt, true
+		}
+	}
+	return t, ok
+}
+
+// isRelatedKey reports whether a and b are the same key or one is nested
+// under the other.
+func isRelatedKey(a, b, delim string) bool {
+	return a == b || strings.HasPrefix(a, b+delim) || strings.HasPrefix(b, a+delim)
+}
@@ -0,0 +1,42 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEntity_ModifiedAt(t *testing.T) {
+	e := New(map[string]interface{}{"name": "jack"}, WithModTimes())
+	if _, ok := e.ModifiedAt("name"); ok {
+		t.Error("ModifiedAt 'name' should be unset before any modification")
+	}
+
+	e.Set("db:host", "localhost")
+	host, ok := e.ModifiedAt("db:host")
+	if !ok {
+		t.Fatal("ModifiedAt 'db:host' should be set")
+	}
+
+	time.Sleep(time.Millisecond)
+	e.Set("db", map[string]interface{}{"host": "remote"})
+	if t2, _ := e.ModifiedAt("db:host"); !t2.After(host) {
+		t.Error("setting a parent key should modify the nested key")
+	}
+	if t3, _ := e.ModifiedAt("db"); t3.Before(host) {
+		t.Error("ModifiedAt 'db' should be after the nested modification")
+	}
+	if _, ok := e.ModifiedAt("dbx"); ok {
+		t.Error("ModifiedAt 'dbx' should be unrelated to 'db'")
+	}
+
+	plain := New(nil)
+	plain.Set("name", "jack")
+	if _, ok := plain.ModifiedAt("name"); ok {
+		t.Error("ModifiedAt should be unset without WithModTimes")
+	}
+}
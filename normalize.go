@@ -0,0 +1,55 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// WithUnicodeKeys makes the Entity NFC-normalize keys on ingestion and
+// lookup, so keys written in decomposed form (NFD, as produced on macOS)
+// resolve the same as precomposed ones. With caseFold set, keys are also
+// case-folded, making lookups case-insensitive across scripts.
+func WithUnicodeKeys(caseFold bool) Option {
+	return func(entity *Entity) {
+		entity.keyTransforms = append(entity.keyTransforms, norm.NFC.String)
+		if caseFold {
+			fold := cases.Fold()
+			entity.keyTransforms = append(entity.keyTransforms, func(key string) string {
+				return fold.String(key)
+			})
+		}
+	}
+}
+
+// WithCaseInsensitive makes the Entity lowercase keys on ingestion and
+// lookup, so "Server:Port" and "server:port" resolve the same value.
+func WithCaseInsensitive() Option {
+	return func(entity *Entity) {
+		entity.keyTransforms = append(entity.keyTransforms, strings.ToLower)
+	}
+}
+
+// WithCaseSensitive makes the Entity store maps passed to Set as they are
+// instead of copying them, so keys are matched exactly and later changes to
+// those maps are visible through the Entity. It has no effect combined with
+// options that transform keys, such as WithCaseInsensitive.
+func WithCaseSensitive() Option {
+	return func(entity *Entity) {
+		entity.noCopy = true
+	}
+}
+
+// normalizeKey applies the key transforms of the Entity to key.
+func (entity *Entity) normalizeKey(key string) string {
+	for _, transform := range entity.keyTransforms {
+		key = transform(key)
+	}
+	return key
+}
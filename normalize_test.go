@@ -0,0 +1,75 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"testing"
+)
+
+const (
+	cafeNFC = "caf\u00e9"
+	cafeNFD = "cafe\u0301"
+)
+
+func TestWithUnicodeKeys(t *testing.T) {
+	e := New(map[string]interface{}{cafeNFD: map[string]interface{}{"name": "jack"}}, WithUnicodeKeys(false))
+	if e.GetString(cafeNFC+":name") != "jack" {
+		t.Error("NFC lookup of an NFD key should resolve")
+	}
+
+	e.Set(cafeNFC+":size", 2)
+	if e.GetInt(cafeNFD+":size") != 2 {
+		t.Error("NFD lookup of an NFC key should resolve")
+	}
+	if e.Get("CAFÉ:name") != nil {
+		t.Error("lookup should stay case-sensitive without case folding")
+	}
+
+	folded := New(map[string]interface{}{"Straße": 1}, WithUnicodeKeys(true))
+	if folded.GetInt("STRASSE") != 1 {
+		t.Error("case-folded lookup should resolve")
+	}
+
+	plain := New(map[string]interface{}{cafeNFD: 1})
+	if plain.Get(cafeNFC) != nil {
+		t.Error("keys should not be normalized without WithUnicodeKeys")
+	}
+}
+
+func TestWithCaseInsensitive(t *testing.T) {
+	e := New(map[string]interface{}{"Server": map[string]interface{}{"Port": 80}}, WithCaseInsensitive())
+	if e.GetInt("server:port") != 80 || e.GetInt("SERVER:PORT") != 80 {
+		t.Error("lookup should ignore case")
+	}
+
+	e.Set("Server:Host", "localhost")
+	if e.GetString("server:host") != "localhost" {
+		t.Error("Set should lowercase the key")
+	}
+	if _, ok := e.GetStringMap("server")["host"]; !ok {
+		t.Error("stored key should be lowercase")
+	}
+
+	e.Set("db", map[string]interface{}{"User": "root"})
+	if e.GetString("DB:user") != "root" {
+		t.Error("Set should lowercase the keys of nested maps")
+	}
+}
+
+func TestWithCaseSensitive(t *testing.T) {
+	e := New(nil, WithCaseSensitive())
+	m := map[string]interface{}{"Name": "jack"}
+	e.Set("user", m)
+
+	if e.Get("user:name") != nil || e.GetString("user:Name") != "jack" {
+		t.Error("lookup should match case exactly")
+	}
+
+	m["Age"] = 18
+	if e.GetInt("user:Age") != 18 {
+		t.Error("Set should store the map without copying it")
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"time"
+)
+
+// Get parses the JSON data and returns the value for the key,
+// for one-off reads that don't need to keep an Entity around.
+func Get(data []byte, key string) interface{} {
+	return NewByJSON(data).Get(key)
+}
+
+// GetString parses the JSON data and returns the value for the key as a string.
+func GetString(data []byte, key string) string {
+	return NewByJSON(data).GetString(key)
+}
+
+// GetBool parses the JSON data and returns the value for the key as a boolean.
+func GetBool(data []byte, key string) bool {
+	return NewByJSON(data).GetBool(key)
+}
+
+// GetInt parses the JSON data and returns the value for the key as an integer.
+func GetInt(data []byte, key string) int {
+	return NewByJSON(data).GetInt(key)
+}
+
+// GetInt64 parses the JSON data and returns the value for the key as an integer.
+func GetInt64(data []byte, key string) int64 {
+	return NewByJSON(data).GetInt64(key)
+}
+
+// GetFloat64 parses the JSON data and returns the value for the key as a float64.
+func GetFloat64(data []byte, key string) float64 {
+	return NewByJSON(data).GetFloat64(key)
+}
+
+// GetTime parses the JSON data and returns the value for the key as time.
+func GetTime(data []byte, key string) time.Time {
+	return NewByJSON(data).GetTime(key)
+}
+
+// GetDuration parses the JSON data and returns the value for the key as a duration.
+func GetDuration(data []byte, key string) time.Duration {
+	return NewByJSON(data).GetDuration(key)
+}
+
+// GetStringSlice parses the JSON data and returns the value for the key as a slice of strings.
+func GetStringSlice(data []byte, key string) []string {
+	return NewByJSON(data).GetStringSlice(key)
+}
+
+// GetStringMap parses the JSON data and returns the value for the key as a map of interfaces.
+func GetStringMap(data []byte, key string) map[string]interface{} {
+	return NewByJSON(data).GetStringMap(key)
+}
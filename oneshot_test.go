@@ -0,0 +1,28 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestGetString(t *testing.T) {
+	f, err := ioutil.ReadFile("test_data.json")
+	if err != nil {
+		t.Fatal("read fail", err)
+	}
+
+	if GetString(f, "event:header:name") != "TextInput" {
+		t.Error("GetString 'event:header:name' val is not TextInput")
+	}
+	if !GetBool(f, "event:simulator") {
+		t.Error("GetBool 'event:simulator' val is not true")
+	}
+	if Get(f, "event:missing") != nil {
+		t.Error("Get 'event:missing' val is not nil")
+	}
+}
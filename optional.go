@@ -0,0 +1,47 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+// Optional holds a value that may be absent.
+type Optional struct {
+	value   interface{}
+	present bool
+}
+
+// GetOptional returns the value associated with the key as an Optional,
+// absent if the key is missing.
+func (entity *Entity) GetOptional(key string) Optional {
+	val := entity.Get(key)
+	return Optional{value: val, present: val != nil}
+}
+
+// IsPresent reports whether the Optional holds a value.
+func (o Optional) IsPresent() bool {
+	return o.present
+}
+
+// Get returns the value of the Optional, or nil if it is absent.
+func (o Optional) Get() interface{} {
+	return o.value
+}
+
+// OrElse returns the value of the Optional, or other if it is absent.
+func (o Optional) OrElse(other interface{}) interface{} {
+	if !o.present {
+		return other
+	}
+	return o.value
+}
+
+// Map returns an Optional holding the result of fn applied to the value,
+// or an absent Optional if o is absent or fn returns nil.
+func (o Optional) Map(fn func(val interface{}) interface{}) Optional {
+	if !o.present {
+		return o
+	}
+	val := fn(o.value)
+	return Optional{value: val, present: val != nil}
+}
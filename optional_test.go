@@ -0,0 +1,39 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cast"
+)
+
+func TestEntity_GetOptional(t *testing.T) {
+	e := New(map[string]interface{}{"name": "jack"})
+
+	name := e.GetOptional("name")
+	if !name.IsPresent() || name.Get() != "jack" {
+		t.Error("GetOptional 'name' should be present")
+	}
+	upper := name.Map(func(val interface{}) interface{} {
+		return strings.ToUpper(cast.ToString(val))
+	})
+	if upper.OrElse("nobody") != "JACK" {
+		t.Errorf("Map result is %v", upper.Get())
+	}
+
+	age := e.GetOptional("age")
+	if age.IsPresent() {
+		t.Error("GetOptional 'age' should be absent")
+	}
+	if age.Map(func(val interface{}) interface{} { return 1 }).IsPresent() {
+		t.Error("Map of an absent Optional should be absent")
+	}
+	if age.OrElse(18) != 18 {
+		t.Error("OrElse of an absent Optional should return the default")
+	}
+}
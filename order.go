@@ -0,0 +1,299 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// WithOrderedKeys makes the Entity remember the order of the keys of its
+// objects, so that parsing, modifying and serializing it keeps the original
+// order. The order is read from JSON and YAML parsed by NewByFormat or
+// UnmarshalJSON, and keys added by Set, Merge and the other mutating methods
+// are appended. ToJSON, ToYAML and Encode with the built-in JSON and YAML
+// codecs emit the keys in that order; keys without a recorded order, such
+// as those of maps passed to Set, follow sorted.
+func WithOrderedKeys() Option {
+	return func(entity *Entity) {
+		entity.order = newKeyOrder()
+	}
+}
+
+// keyOrder records the order of the keys of the objects of an Entity,
+// indexed by the path of each object joined with orderSep.
+type keyOrder struct {
+	keys map[string][]string
+	seen map[string]bool
+}
+
+// orderSep joins paths in a keyOrder; unlike the key delimiter, it cannot
+// occur in keys.
+const orderSep = "\x00"
+
+func newKeyOrder() *keyOrder {
+	return &keyOrder{keys: make(map[string][]string), seen: make(map[string]bool)}
+}
+
+// add records each key of the normalized path not yet recorded as the last
+// key of its object. It does nothing on a nil keyOrder.
+func (o *keyOrder) add(path []string) {
+	if o == nil {
+		return
+	}
+	parent := ""
+	for i, k := range path {
+		key := k
+		if i > 0 {
+			key = parent + orderSep + k
+		}
+		if !o.seen[key] {
+			o.seen[key] = true
+			o.keys[parent] = append(o.keys[parent], k)
+		}
+		parent = key
+	}
+}
+
+// remove forgets the last key of the normalized path and the keys nested
+// under it. It does nothing on a nil keyOrder.
+func (o *keyOrder) remove(path []string) {
+	if o == nil || len(path) == 0 {
+		return
+	}
+	key := strings.Join(path, orderSep)
+	parent := strings.Join(path[:len(path)-1], orderSep)
+	for k := range o.seen {
+		if k == key || strings.HasPrefix(k, key+orderSep) {
+			delete(o.seen, k)
+		}
+	}
+	for k := range o.keys {
+		if k == key || strings.HasPrefix(k, key+orderSep) {
+			delete(o.keys, k)
+		}
+	}
+	keys := o.keys[parent]
+	for i, k := range keys {
+		if k == path[len(path)-1] {
+			o.keys[parent] = append(keys[:i:i], keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// orderSnapshot returns a copy of the key order of the Entity, or nil.
+func (entity *Entity) orderSnapshot() *keyOrder {
+	entity.mu.RLock()
+	defer entity.mu.RUnlock()
+
+	return entity.order.clone()
+}
+
+// clone returns a copy of the keyOrder, or nil.
+func (o *keyOrder) clone() *keyOrder {
+	if o == nil {
+		return nil
+	}
+	c := newKeyOrder()
+	for k, v := range o.keys {
+		c.keys[k] = append([]string(nil), v...)
+	}
+	for k := range o.seen {
+		c.seen[k] = true
+	}
+	return c
+}
+
+// sorted returns the keys of m, the object at the path joined as parent, in
+// the recorded order followed by the other keys sorted.
+func (o *keyOrder) sorted(parent string, m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	done := make(map[string]bool, len(m))
+	for _, k := range o.keys[parent] {
+		if _, ok := m[k]; ok && !done[k] {
+			done[k] = true
+			keys = append(keys, k)
+		}
+	}
+	rest := len(keys)
+	for k := range m {
+		if !done[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys[rest:])
+	return keys
+}
+
+// readJSON records the key order of the JSON document data, passing the
+// keys through normalize.
+func (o *keyOrder) readJSON(data []byte, normalize func(string) string) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	return o.readJSONValue(dec, nil, normalize)
+}
+
+func (o *keyOrder) readJSONValue(dec *json.Decoder, path []string, normalize func(string) string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok {
+	case json.Delim('{'):
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			p := append(path[:len(path):len(path)], normalize(fmt.Sprint(tok)))
+			o.add(p)
+			if err := o.readJSONValue(dec, p, normalize); err != nil {
+				return err
+			}
+		}
+	case json.Delim('['):
+		for i := 0; dec.More(); i++ {
+			p := append(path[:len(path):len(path)], strconv.Itoa(i))
+			if err := o.readJSONValue(dec, p, normalize); err != nil {
+				return err
+			}
+		}
+	default:
+		return nil
+	}
+	_, err = dec.Token()
+	return err
+}
+
+// readYAML records the key order of the YAML document data, passing the
+// keys through normalize.
+func (o *keyOrder) readYAML(data []byte, normalize func(string) string) error {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	o.readYAMLValue(doc, nil, normalize)
+	return nil
+}
+
+func (o *keyOrder) readYAMLValue(v interface{}, path []string, normalize func(string) string) {
+	switch v := v.(type) {
+	case yaml.MapSlice:
+		for _, item := range v {
+			p := append(path[:len(path):len(path)], normalize(fmt.Sprint(item.Key)))
+			o.add(p)
+			o.readYAMLValue(item.Value, p, normalize)
+		}
+	case []interface{}:
+		for i, val := range v {
+			o.readYAMLValue(val, append(path[:len(path):len(path)], strconv.Itoa(i)), normalize)
+		}
+	}
+}
+
+// read records the key order of data parsed by c, if c is a built-in codec
+// preserving it.
+func (o *keyOrder) read(c Codec, data []byte, normalize func(string) string) error {
+	switch c.(type) {
+	case jsonCodec:
+		return o.readJSON(data, normalize)
+	case yamlCodec:
+		return o.readYAML(data, normalize)
+	}
+	return nil
+}
+
+// encode serializes data with c in the recorded order, and reports whether
+// c is a built-in codec preserving it. A nil keyOrder preserves no order.
+func (o *keyOrder) encode(c Codec, data map[string]interface{}) ([]byte, bool, error) {
+	if o == nil {
+		return nil, false, nil
+	}
+	switch c.(type) {
+	case jsonCodec:
+		var b bytes.Buffer
+		err := o.writeJSON(&b, "", data)
+		return b.Bytes(), true, err
+	case yamlCodec:
+		b, err := yaml.Marshal(o.mapSlice("", data))
+		return b, true, err
+	}
+	return nil, false, nil
+}
+
+// writeJSON writes v, the value at the path joined as parent, as JSON to b.
+func (o *keyOrder) writeJSON(b *bytes.Buffer, parent string, v interface{}) error {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		b.WriteByte('{')
+		for i, k := range o.sorted(parent, v) {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			key, _ := json.Marshal(k)
+			b.Write(key)
+			b.WriteByte(':')
+			if err := o.writeJSON(b, joinOrder(parent, k), v[k]); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	case []interface{}:
+		b.WriteByte('[')
+		for i, val := range v {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := o.writeJSON(b, joinOrder(parent, strconv.Itoa(i)), val); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	default:
+		val, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		b.Write(val)
+	}
+	return nil
+}
+
+// mapSlice converts v, the value at the path joined as parent, into values
+// that YAML serializes in the recorded order.
+func (o *keyOrder) mapSlice(parent string, v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		s := make(yaml.MapSlice, 0, len(v))
+		for _, k := range o.sorted(parent, v) {
+			s = append(s, yaml.MapItem{Key: k, Value: o.mapSlice(joinOrder(parent, k), v[k])})
+		}
+		return s
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = o.mapSlice(joinOrder(parent, strconv.Itoa(i)), val)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+// joinOrder returns the path of key in the object at the path joined as
+// parent.
+func joinOrder(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + orderSep + key
+}
@@ -0,0 +1,55 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWithOrderedKeys(t *testing.T) {
+	src := `{"zeta":1,"alpha":{"y":true,"x":[{"b":1,"a":2}]},"mid":"m"}`
+	e, err := NewByFormat("json", []byte(src), WithOrderedKeys())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := e.ToJSON(); err != nil || string(b) != src {
+		t.Errorf("ToJSON is %s, %v", b, err)
+	}
+
+	e.Set("beta", 2).Set("alpha:w", 3).Delete("zeta")
+	e.Set("zeta", 4)
+	e.MergeMap(map[string]interface{}{"gamma": map[string]interface{}{"q": 1, "p": 2}})
+	want := `{"alpha":{"y":true,"x":[{"b":1,"a":2}],"w":3},"mid":"m","beta":2,"zeta":4,"gamma":{"p":2,"q":1}}`
+	if b, _ := e.ToJSON(); string(b) != want {
+		t.Errorf("ToJSON after changes is %s", b)
+	}
+	if b, _ := e.Clone().ToJSON(); string(b) != want {
+		t.Errorf("Clone ToJSON is %s", b)
+	}
+	b, _ := e.ToJSONIndent("", "  ")
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, b); err != nil || compact.String() != want {
+		t.Errorf("ToJSONIndent is %s", b)
+	}
+
+	y, err := NewByFormat("yaml", []byte("zeta: 1\nalpha:\n  b: true\n  a: 2\n"), WithOrderedKeys())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := y.ToYAML(); string(b) != "zeta: 1\nalpha:\n  b: true\n  a: 2\n" {
+		t.Errorf("ToYAML is %q", b)
+	}
+
+	u := New(nil, WithOrderedKeys())
+	if err := json.Unmarshal([]byte(`{"b":1,"a":2}`), u); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := u.ToJSON(); string(b) != `{"b":1,"a":2}` {
+		t.Errorf("ToJSON after UnmarshalJSON is %s", b)
+	}
+}
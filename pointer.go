@@ -0,0 +1,300 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+var (
+	// ErrInvalidPointer is returned for a malformed JSON Pointer.
+	ErrInvalidPointer = errors.New("invalid JSON pointer")
+	// ErrPatchTest is returned by ApplyPatch when a "test" operation fails.
+	ErrPatchTest = errors.New("test failed")
+)
+
+// parsePointer returns the normalized path of the JSON Pointer (RFC 6901).
+// The empty pointer refers to the whole document and has an empty path.
+func (entity *Entity) parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w %q", ErrInvalidPointer, pointer)
+	}
+	path := strings.Split(pointer[1:], "/")
+	for i, token := range path {
+		token = strings.Replace(token, "~1", "/", -1)
+		path[i] = entity.normalizeKey(strings.Replace(token, "~0", "~", -1))
+	}
+	return path, nil
+}
+
+// GetByPointer returns the value at the JSON Pointer (RFC 6901), such as
+// "/event/header/name" or "/clientContext/0", or nil if there is none.
+func (entity *Entity) GetByPointer(pointer string) interface{} {
+	entity.evictExpired()
+	entity.mu.RLock()
+	defer entity.mu.RUnlock()
+
+	path, err := entity.parsePointer(pointer)
+	if err != nil {
+		return nil
+	}
+	v, _ := entity.lookupPath(path)
+	return v
+}
+
+// SetByPointer sets the value at the JSON Pointer (RFC 6901) like SetE sets
+// the value for a key. A last token of "-" appends to an array.
+func (entity *Entity) SetByPointer(pointer string, value interface{}) error {
+	entity.evictExpired()
+	entity.mu.Lock()
+	defer entity.unlock()
+
+	path, err := entity.parsePointer(pointer)
+	if err != nil {
+		return err
+	}
+	if len(path) == 0 {
+		return entity.replaceRoot(value)
+	}
+	if path[len(path)-1] == "-" {
+		if parent, ok := entity.lookupPath(path[:len(path)-1]); ok {
+			if s, err := cast.ToSliceE(parent); err == nil {
+				path[len(path)-1] = strconv.Itoa(len(s))
+			}
+		}
+	}
+	key := strings.Join(path, entity.keyDelim)
+	entity.clearTTL(key)
+	return entity.assign(key, path, value)
+}
+
+// replaceRoot replaces the whole data of the Entity with the object value.
+func (entity *Entity) replaceRoot(value interface{}) error {
+	m, err := cast.ToStringMapE(value)
+	if err != nil {
+		return fmt.Errorf("document root must be an object, not %T", value)
+	}
+	if hasCycle(m, make(map[container]bool)) {
+		return ErrCircularReference
+	}
+	entity.replaceData(copyAndInsensitiveMap(m, entity.normalizeKey))
+	return nil
+}
+
+// patchOperation is an operation of a JSON Patch (RFC 6902).
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ApplyPatch applies the JSON Patch (RFC 6902) document patch to the Entity.
+// Either all operations are applied or, if one fails, none of them.
+func (entity *Entity) ApplyPatch(patch []byte) error {
+	var ops []patchOperation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return err
+	}
+
+	entity.evictExpired()
+	entity.mu.Lock()
+	defer entity.unlock()
+
+	saved := entity.save()
+	for i, op := range ops {
+		if err := entity.applyOperation(op); err != nil {
+			entity.restore(saved)
+			return fmt.Errorf("patch operation %d (%s %q): %w", i, op.Op, op.Path, err)
+		}
+	}
+	return nil
+}
+
+// state is the data of an Entity with its bookkeeping, saved to roll back
+// a failed ApplyPatch.
+type state struct {
+	data     map[string]interface{}
+	expires  map[string]time.Time
+	modTimes map[string]time.Time
+	changes  map[string]tracked
+	version  uint64
+	events   int
+}
+
+// save returns the current state of the Entity without locking.
+func (entity *Entity) save() state {
+	s := state{version: entity.version, events: len(entity.events)}
+	s.data, _ = deepCopy(entity.data).(map[string]interface{})
+	if entity.expires != nil {
+		s.expires = make(map[string]time.Time, len(entity.expires))
+		for k, v := range entity.expires {
+			s.expires[k] = v
+		}
+	}
+	if entity.modTimes != nil {
+		s.modTimes = make(map[string]time.Time, len(entity.modTimes))
+		for k, v := range entity.modTimes {
+			s.modTimes[k] = v
+		}
+	}
+	if entity.changes != nil {
+		s.changes = make(map[string]tracked, len(entity.changes))
+		for k, v := range entity.changes {
+			s.changes[k] = v
+		}
+	}
+	return s
+}
+
+// restore returns the Entity to s without locking, dropping the changes
+// queued for watchers since.
+func (entity *Entity) restore(s state) {
+	entity.data = s.data
+	entity.expires = s.expires
+	entity.modTimes = s.modTimes
+	entity.changes = s.changes
+	entity.version = s.version
+	entity.events = entity.events[:s.events]
+}
+
+// applyOperation applies op without locking.
+func (entity *Entity) applyOperation(op patchOperation) error {
+	path, err := entity.parsePointer(op.Path)
+	if err != nil {
+		return err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if len(op.Value) == 0 {
+			return errors.New("missing value")
+		}
+		var value interface{}
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return err
+		}
+		switch op.Op {
+		case "add":
+			return entity.addPointer(path, value)
+		case "replace":
+			if _, ok := entity.lookupPath(path); !ok {
+				return ErrKeyNotFound
+			}
+			if len(path) == 0 {
+				return entity.replaceRoot(value)
+			}
+			return entity.assign(strings.Join(path, entity.keyDelim), path, value)
+		default:
+			cur, ok := entity.lookupPath(path)
+			if !ok {
+				return ErrKeyNotFound
+			}
+			if !jsonEqual(cur, value) {
+				return ErrPatchTest
+			}
+			return nil
+		}
+	case "remove":
+		if len(path) == 0 || !entity.unset(path, false) {
+			return ErrKeyNotFound
+		}
+		return nil
+	case "move", "copy":
+		from, err := entity.parsePointer(op.From)
+		if err != nil {
+			return err
+		}
+		value, ok := entity.lookupPath(from)
+		if !ok {
+			return fmt.Errorf("from %q: %w", op.From, ErrKeyNotFound)
+		}
+		value = deepCopy(value)
+		if op.Op == "move" {
+			if isPathPrefix(from, path) {
+				return errors.New("cannot move a value into itself")
+			}
+			if len(from) == 0 || !entity.unset(from, false) {
+				return ErrKeyNotFound
+			}
+		}
+		return entity.addPointer(path, value)
+	default:
+		return fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+// addPointer adds value at path as the JSON Patch "add" operation does:
+// members of objects are set, while elements are inserted into arrays.
+func (entity *Entity) addPointer(path []string, value interface{}) error {
+	if len(path) == 0 {
+		return entity.replaceRoot(value)
+	}
+	parentPath, last := path[:len(path)-1], path[len(path)-1]
+	parent, ok := entity.lookupPath(parentPath)
+	if !ok {
+		return ErrKeyNotFound
+	}
+
+	switch p := parent.(type) {
+	case map[string]interface{}, map[interface{}]interface{}:
+		return entity.assign(strings.Join(path, entity.keyDelim), path, value)
+	case []interface{}, []map[string]interface{}:
+		s := cast.ToSlice(p)
+		idx := len(s)
+		if last != "-" {
+			i, err := strconv.Atoi(last)
+			if err != nil || i < 0 || i > len(s) {
+				return fmt.Errorf("index %q out of range for array of length %d", last, len(s))
+			}
+			idx = i
+		}
+		inserted := make([]interface{}, 0, len(s)+1)
+		inserted = append(inserted, s[:idx]...)
+		inserted = append(inserted, value)
+		inserted = append(inserted, s[idx:]...)
+		return entity.assign(strings.Join(parentPath, entity.keyDelim), parentPath, inserted)
+	default:
+		return fmt.Errorf("cannot add to %T", parent)
+	}
+}
+
+// isPathPrefix reports whether prefix is a proper prefix of path.
+func isPathPrefix(prefix, path []string) bool {
+	if len(prefix) >= len(path) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonEqual reports whether a and b have the same JSON representation,
+// so that numbers of different types compare by value.
+func jsonEqual(a, b interface{}) bool {
+	var norm [2]interface{}
+	for i, v := range []interface{}{a, b} {
+		data, err := json.Marshal(v)
+		if err != nil || json.Unmarshal(data, &norm[i]) != nil {
+			return false
+		}
+	}
+	return reflect.DeepEqual(norm[0], norm[1])
+}
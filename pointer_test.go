@@ -0,0 +1,136 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+func TestEntity_GetByPointer(t *testing.T) {
+	f, err := ioutil.ReadFile("test_data.json")
+	if err != nil {
+		t.Fatal("read fail", err)
+	}
+	e := NewByJSON(f)
+	e.Set("paths", map[string]interface{}{"a/b": 1, "m~n": 2, "x:y": 3})
+
+	for pointer, want := range map[string]interface{}{
+		"/event/simulator": true,
+		"/clientContext/1/payload/offsetInMilliseconds": float64(1023785),
+		"/paths/a~1b":     1,
+		"/paths/m~0n":     2,
+		"/paths/x:y":      3,
+		"/missing":        nil,
+		"event/simulator": nil,
+	} {
+		if v := e.GetByPointer(pointer); v != want {
+			t.Errorf("GetByPointer %q is %v, not %v", pointer, v, want)
+		}
+	}
+	if !reflect.DeepEqual(e.GetByPointer(""), e.GetData()) {
+		t.Error("GetByPointer of the empty pointer should be the whole document")
+	}
+}
+
+func TestEntity_SetByPointer(t *testing.T) {
+	e := NewByJSON([]byte(`{"list": [1]}`))
+
+	if err := e.SetByPointer("/redis/user:1", "jack"); err != nil {
+		t.Fatal("SetByPointer error:", err)
+	}
+	if err := e.SetByPointer("/list/-", 2); err != nil {
+		t.Fatal("SetByPointer append error:", err)
+	}
+	if e.GetByPointer("/redis/user:1") != "jack" || len(e.GetSlice("list")) != 2 {
+		t.Errorf("SetByPointer result is %v", e.GetData())
+	}
+	if err := e.SetByPointer("list", 1); !errors.Is(err, ErrInvalidPointer) {
+		t.Errorf("SetByPointer of an invalid pointer error is %v", err)
+	}
+}
+
+func TestEntity_ApplyPatch(t *testing.T) {
+	e := NewByJSON([]byte(`{"name": "jack", "tags": ["a", "c"], "address": {"city": "Beijing"}}`))
+	err := e.ApplyPatch([]byte(`[
+		{"op": "test", "path": "/name", "value": "jack"},
+		{"op": "add", "path": "/tags/1", "value": "b"},
+		{"op": "add", "path": "/tags/-", "value": "d"},
+		{"op": "replace", "path": "/name", "value": "rose"},
+		{"op": "copy", "from": "/address", "path": "/home"},
+		{"op": "move", "from": "/address/city", "path": "/city"},
+		{"op": "remove", "path": "/address"}
+	]`))
+	if err != nil {
+		t.Fatal("ApplyPatch error:", err)
+	}
+	want := NewByJSON([]byte(`{"name": "rose", "tags": ["a", "b", "c", "d"], "home": {"city": "Beijing"}, "city": "Beijing"}`))
+	if !reflect.DeepEqual(e.GetData(), want.GetData()) {
+		t.Errorf("ApplyPatch result is %v", e.GetData())
+	}
+
+	for _, patch := range []string{
+		`[{"op": "replace", "path": "/name", "value": "lily"}, {"op": "test", "path": "/name", "value": "jack"}]`,
+		`[{"op": "remove", "path": "/missing"}]`,
+		`[{"op": "add", "path": "/tags/9", "value": 1}]`,
+		`[{"op": "move", "from": "/home", "path": "/home/inner"}]`,
+		`[{"op": "add", "path": "/a/b", "value": 1}]`,
+		`[{"op": "jump", "path": "/name"}]`,
+	} {
+		if err := e.ApplyPatch([]byte(patch)); err == nil {
+			t.Errorf("ApplyPatch %s should fail", patch)
+		}
+	}
+	if !reflect.DeepEqual(e.GetData(), want.GetData()) {
+		t.Errorf("a failed patch should leave the data unchanged, got %v", e.GetData())
+	}
+}
+
+func TestEntity_PointerBookkeeping(t *testing.T) {
+	e := New(map[string]interface{}{"redis": map[string]interface{}{"user:1": "jack"}, "old": 1},
+		WithChangeTracking(), WithModTimes())
+	var keys []string
+	cancel := e.Watch("", func(key string, old, new interface{}) {
+		keys = append(keys, key)
+	})
+	defer cancel()
+
+	if err := e.SetByPointer("/redis/user:1", "rose"); err != nil {
+		t.Fatal("SetByPointer error:", err)
+	}
+	if c := e.Changes()["redis:user:1"]; c.Old != "jack" || c.New != "rose" {
+		t.Errorf("a token containing the delimiter is tracked as %v", c)
+	}
+
+	if err := e.SetByPointer("", map[string]interface{}{"new": 2}); err != nil {
+		t.Fatal("SetByPointer root error:", err)
+	}
+	if !reflect.DeepEqual(keys, []string{"redis:user:1", "new", "old", "redis"}) {
+		t.Errorf("watched keys are %v", keys)
+	}
+	if c := e.Changes()["old"]; c.Old != 1 || c.New != nil {
+		t.Errorf("replacing the root should track removed keys, got %v", c)
+	}
+	if _, ok := e.ModifiedAt("new"); !ok {
+		t.Error("replacing the root should record modification times")
+	}
+
+	version := e.Version()
+	if err := e.ApplyPatch([]byte(`[{"op": "add", "path": "/x", "value": 1}, {"op": "remove", "path": "/missing"}]`)); err == nil {
+		t.Fatal("ApplyPatch should fail")
+	}
+	if e.Version() != version || e.Has("x") || len(keys) != 4 {
+		t.Error("a failed patch should restore the version and notify no watcher")
+	}
+	if _, ok := e.ModifiedAt("x"); ok {
+		t.Error("a failed patch should restore the modification times")
+	}
+	if _, ok := e.Changes()["x"]; ok {
+		t.Error("a failed patch should restore the tracked changes")
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// Condition reports whether an element of an object array matches.
+type Condition func(el *Entity) bool
+
+// Field returns a Condition comparing the value of field with value using op.
+// Supported ops are ==, !=, <, <=, >, >=, contains and in.
+func Field(field, op string, value interface{}) Condition {
+	return func(el *Entity) bool {
+		return match(el.Get(field), op, value)
+	}
+}
+
+// And returns a Condition matching when all of conds match.
+func And(conds ...Condition) Condition {
+	return func(el *Entity) bool {
+		for _, cond := range conds {
+			if !cond(el) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or returns a Condition matching when any of conds matches.
+func Or(conds ...Condition) Condition {
+	return func(el *Entity) bool {
+		for _, cond := range conds {
+			if cond(el) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Where returns the elements of the object array at key whose field
+// compares to value using op, e.g. e.Where("orders", "status", "==", "open").
+func (entity *Entity) Where(key, field, op string, value interface{}) []map[string]interface{} {
+	return entity.WhereCond(key, Field(field, op, value))
+}
+
+// WhereCond returns the elements of the object array at key matching cond.
+func (entity *Entity) WhereCond(key string, cond Condition) []map[string]interface{} {
+	var s []map[string]interface{}
+	for _, m := range entity.GetStringMapSlice(key) {
+		if cond(entity.child(m)) {
+			s = append(s, m)
+		}
+	}
+	return s
+}
+
+// FindFirst returns the first element of the object array at key matching
+// pred, and whether one was found.
+func (entity *Entity) FindFirst(key string, pred Condition) (*Entity, bool) {
+	for _, m := range entity.GetStringMapSlice(key) {
+		if el := entity.child(m); pred(el) {
+			return el, true
+		}
+	}
+	return nil, false
+}
+
+// CountWhere returns the number of elements of the object array at key
+// matching pred.
+func (entity *Entity) CountWhere(key string, pred Condition) int {
+	n := 0
+	for _, m := range entity.GetStringMapSlice(key) {
+		if pred(entity.child(m)) {
+			n++
+		}
+	}
+	return n
+}
+
+// CountWhereField returns the number of elements of the object array at key
+// whose field compares to value using op.
+func (entity *Entity) CountWhereField(key, field, op string, value interface{}) int {
+	return entity.CountWhere(key, Field(field, op, value))
+}
+
+// match applies op to a and b.
+func match(a interface{}, op string, b interface{}) bool {
+	switch op {
+	case "contains":
+		return contains(a, b)
+	case "in":
+		return contains(b, a)
+	}
+
+	c, ok := compare(a, b)
+	if !ok {
+		return op == "!="
+	}
+	switch op {
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	default:
+		return false
+	}
+}
+
+// contains reports whether the slice or string a contains b.
+func contains(a, b interface{}) bool {
+	if s, ok := a.(string); ok {
+		return strings.Contains(s, cast.ToString(b))
+	}
+	for _, v := range cast.ToSlice(a) {
+		if c, ok := compare(v, b); ok && c == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// compare compares a and b numerically, as times or as strings,
+// in that order of preference.
+// The result is 0 if a == b, -1 if a < b, and +1 if a > b.
+// ok is false if either value is missing.
+func compare(a, b interface{}) (c int, ok bool) {
+	if a == nil || b == nil {
+		return 0, a == nil && b == nil
+	}
+
+	if isNumber(a) || isNumber(b) {
+		fa, errA := cast.ToFloat64E(a)
+		fb, errB := cast.ToFloat64E(b)
+		if errA == nil && errB == nil {
+			return compareFloat64(fa, fb), true
+		}
+	}
+
+	_, timeA := a.(time.Time)
+	_, timeB := b.(time.Time)
+	if timeA || timeB {
+		ta, errA := cast.ToTimeE(a)
+		tb, errB := cast.ToTimeE(b)
+		if errA == nil && errB == nil {
+			return compareFloat64(float64(ta.Sub(tb)), 0), true
+		}
+	}
+
+	// timestamps decoded from JSON or YAML are strings, possibly in
+	// different zones
+	if ta, ok := parseTime(a); ok {
+		if tb, ok := parseTime(b); ok {
+			return compareFloat64(float64(ta.Sub(tb)), 0), true
+		}
+	}
+
+	return strings.Compare(cast.ToString(a), cast.ToString(b)), true
+}
+
+// parseTime parses v as a time if it is a string that looks like one.
+func parseTime(v interface{}) (time.Time, bool) {
+	s, ok := v.(string)
+	if !ok || s == "" || s[0] < '0' || s[0] > '9' {
+		return time.Time{}, false
+	}
+	t, err := cast.ToTimeE(s)
+	return t, err == nil
+}
+
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// isNumber reports whether v holds a numeric type.
+func isNumber(v interface{}) bool {
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"testing"
+)
+
+var ordersJSON = []byte(`{"orders": [
+	{"id": 1, "status": "open", "total": 120, "tags": ["gift"]},
+	{"id": 2, "status": "closed", "total": 80, "tags": []},
+	{"id": 3, "status": "open", "total": 40, "tags": ["gift", "rush"]}
+]}`)
+
+func TestEntity_Where(t *testing.T) {
+	e := NewByJSON(ordersJSON)
+
+	open := e.Where("orders", "status", "==", "open")
+	if len(open) != 2 {
+		t.Fatalf("Where status == open len is %d, not 2", len(open))
+	}
+
+	big := e.Where("orders", "total", ">=", 80)
+	if len(big) != 2 || big[1]["id"] != float64(2) {
+		t.Errorf("Where total >= 80 is %v", big)
+	}
+
+	gift := e.Where("orders", "tags", "contains", "rush")
+	if len(gift) != 1 || gift[0]["id"] != float64(3) {
+		t.Errorf("Where tags contains rush is %v", gift)
+	}
+}
+
+func TestEntity_WhereCond(t *testing.T) {
+	e := NewByJSON(ordersJSON)
+
+	s := e.WhereCond("orders", Or(
+		And(Field("status", "==", "open"), Field("total", "<", 100)),
+		Field("id", "in", []interface{}{2}),
+	))
+	if len(s) != 2 || s[0]["id"] != float64(2) || s[1]["id"] != float64(3) {
+		t.Errorf("WhereCond result is %v", s)
+	}
+}
+
+func TestEntity_FindFirst(t *testing.T) {
+	e := NewByJSON(ordersJSON)
+
+	el, ok := e.FindFirst("orders", func(el *Entity) bool {
+		return el.GetInt("total") < 100
+	})
+	if !ok || el.GetInt("id") != 2 {
+		t.Errorf("FindFirst total < 100 is %v", el)
+	}
+
+	if _, ok := e.FindFirst("orders", Field("status", "==", "void")); ok {
+		t.Error("FindFirst status == void should not match")
+	}
+}
+
+func TestEntity_CountWhere(t *testing.T) {
+	e := NewByJSON(ordersJSON)
+
+	if n := e.CountWhereField("orders", "status", "==", "open"); n != 2 {
+		t.Errorf("CountWhereField status == open is %d, not 2", n)
+	}
+	if n := e.CountWhere("orders", func(el *Entity) bool {
+		return len(el.GetSlice("tags")) == 0
+	}); n != 1 {
+		t.Errorf("CountWhere without tags is %d, not 1", n)
+	}
+}
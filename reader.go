@@ -0,0 +1,121 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"time"
+)
+
+// Reader reads typed values from an Entity, collecting errors along the way
+// so a series of reads can be checked once with Err.
+// A missing key or a value that cannot be converted is an error; the read
+// then returns the zero value.
+type Reader struct {
+	entity *Entity
+	errs   []error
+}
+
+// Reader returns a Reader over the Entity.
+func (entity *Entity) Reader() *Reader {
+	return &Reader{entity: entity}
+}
+
+// Err returns the first error encountered by the Reader, or nil.
+func (r *Reader) Err() error {
+	if len(r.errs) == 0 {
+		return nil
+	}
+	return r.errs[0]
+}
+
+// Errs returns all errors encountered by the Reader.
+func (r *Reader) Errs() []error {
+	return r.errs
+}
+
+// record keeps err, if any.
+func (r *Reader) record(err error) {
+	if err != nil {
+		r.errs = append(r.errs, err)
+	}
+}
+
+// String returns the value associated with the key as a string.
+func (r *Reader) String(key string) string {
+	v, err := r.entity.GetStringE(key)
+	r.record(err)
+	return v
+}
+
+// Bool returns the value associated with the key as a boolean.
+func (r *Reader) Bool(key string) bool {
+	v, err := r.entity.GetBoolE(key)
+	r.record(err)
+	return v
+}
+
+// Int returns the value associated with the key as an integer.
+func (r *Reader) Int(key string) int {
+	v, err := r.entity.GetIntE(key)
+	r.record(err)
+	return v
+}
+
+// Int64 returns the value associated with the key as an integer.
+func (r *Reader) Int64(key string) int64 {
+	v, err := r.entity.GetInt64E(key)
+	r.record(err)
+	return v
+}
+
+// Uint returns the value associated with the key as an unsigned integer.
+func (r *Reader) Uint(key string) uint {
+	v, err := r.entity.GetUintE(key)
+	r.record(err)
+	return v
+}
+
+// Float64 returns the value associated with the key as a float64.
+func (r *Reader) Float64(key string) float64 {
+	v, err := r.entity.GetFloat64E(key)
+	r.record(err)
+	return v
+}
+
+// Time returns the value associated with the key as time.
+func (r *Reader) Time(key string) time.Time {
+	v, err := r.entity.GetTimeE(key)
+	r.record(err)
+	return v
+}
+
+// Duration returns the value associated with the key as a duration.
+func (r *Reader) Duration(key string) time.Duration {
+	v, err := r.entity.GetDurationE(key)
+	r.record(err)
+	return v
+}
+
+// StringSlice returns the value associated with the key as a slice of strings.
+func (r *Reader) StringSlice(key string) []string {
+	v, err := r.entity.GetStringSliceE(key)
+	r.record(err)
+	return v
+}
+
+// IntSlice returns the value associated with the key as a slice of int values.
+func (r *Reader) IntSlice(key string) []int {
+	v, err := r.entity.GetIntSliceE(key)
+	r.record(err)
+	return v
+}
+
+// StringMap returns the value associated with the key as a map of interfaces.
+func (r *Reader) StringMap(key string) map[string]interface{} {
+	v, err := r.entity.GetStringMapE(key)
+	r.record(err)
+	return v
+}
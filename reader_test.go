@@ -0,0 +1,36 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEntity_Reader(t *testing.T) {
+	e := NewByJSON([]byte(`{"db": {"host": "localhost", "port": 5432, "timeout": "3s", "debug": "yes"}}`))
+
+	r := e.Reader()
+	host := r.String("db:host")
+	port := r.Int("db:port")
+	timeout := r.Duration("db:timeout")
+	if err := r.Err(); err != nil {
+		t.Fatal("Reader error:", err)
+	}
+	if host != "localhost" || port != 5432 || timeout != 3*time.Second {
+		t.Errorf("Reader values are %v %v %v", host, port, timeout)
+	}
+
+	r.Bool("db:debug")
+	r.String("db:user")
+	if len(r.Errs()) != 2 {
+		t.Fatalf("Reader errors are %v", r.Errs())
+	}
+	if !errors.Is(r.Errs()[0], ErrInvalidType) || !errors.Is(r.Errs()[1], ErrKeyNotFound) {
+		t.Errorf("Reader errors are %v", r.Errs())
+	}
+}
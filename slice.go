@@ -0,0 +1,301 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cast"
+)
+
+// fieldOf returns the value of field in the array element el.
+func (entity *Entity) fieldOf(el interface{}, field string) interface{} {
+	m, err := cast.ToStringMapE(el)
+	if err != nil {
+		return nil
+	}
+	return entity.child(m).Get(field)
+}
+
+// SortSlice sorts the array of objects at key by field, ascending unless desc
+// is set. Numbers, times and strings are compared by value, and strings
+// holding timestamps are compared as times; elements missing field are
+// placed last.
+func (entity *Entity) SortSlice(key, field string, desc bool) *Entity {
+	entity.update(key, func(val interface{}) (interface{}, error) {
+		s, err := cast.ToSliceE(val)
+		if err != nil {
+			return nil, errNoChange
+		}
+
+		sorted := make([]interface{}, len(s))
+		copy(sorted, s)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			a := entity.fieldOf(sorted[i], field)
+			b := entity.fieldOf(sorted[j], field)
+			if a == nil || b == nil {
+				return a != nil
+			}
+			c, _ := compare(a, b)
+			if desc {
+				return c > 0
+			}
+			return c < 0
+		})
+		return sorted, nil
+	})
+	return entity
+}
+
+// GroupBy groups the array of objects at key by the string value of field.
+// Elements missing field are grouped under "".
+func (entity *Entity) GroupBy(key, field string) map[string][]*Entity {
+	groups := make(map[string][]*Entity)
+	for _, m := range entity.GetStringMapSlice(key) {
+		el := entity.child(m)
+		k := el.GetString(field)
+		groups[k] = append(groups[k], el)
+	}
+	return groups
+}
+
+// JoinKind selects the semantics of JoinSlices.
+type JoinKind int
+
+const (
+	// InnerJoin keeps only left elements with a matching right element.
+	InnerJoin JoinKind = iota
+	// LeftJoin keeps every left element, merged with its matches if any.
+	LeftJoin
+)
+
+// JoinSlices joins the object arrays at leftKey and rightKey, pairing
+// elements whose leftField and rightField values are equal.
+// Each pair is merged into a new object; on conflicting fields the left
+// element wins. A left element matching several right elements yields one
+// result per match.
+func (entity *Entity) JoinSlices(leftKey, rightKey, leftField, rightField string, kind JoinKind) []map[string]interface{} {
+	index := make(map[string][]map[string]interface{})
+	for _, m := range entity.GetStringMapSlice(rightKey) {
+		v := entity.child(m).Get(rightField)
+		if v == nil {
+			continue
+		}
+		k := cast.ToString(v)
+		index[k] = append(index[k], m)
+	}
+
+	var s []map[string]interface{}
+	for _, left := range entity.GetStringMapSlice(leftKey) {
+		var matches []map[string]interface{}
+		if v := entity.child(left).Get(leftField); v != nil {
+			matches = index[cast.ToString(v)]
+		}
+		if len(matches) == 0 {
+			if kind == LeftJoin {
+				s = append(s, joinMaps(left, nil))
+			}
+			continue
+		}
+		for _, right := range matches {
+			s = append(s, joinMaps(left, right))
+		}
+	}
+	return s
+}
+
+// joinMaps returns a new map holding the fields of left and right,
+// preferring left on conflicts.
+func joinMaps(left, right map[string]interface{}) map[string]interface{} {
+	m := make(map[string]interface{}, len(left)+len(right))
+	for k, v := range right {
+		m[k] = v
+	}
+	for k, v := range left {
+		m[k] = v
+	}
+	return m
+}
+
+// distinctKey returns a comparable identity for v.
+func distinctKey(v interface{}) string {
+	return fmt.Sprintf("%T:%v", v, v)
+}
+
+// Distinct returns the values of the array at key with duplicates removed,
+// keeping the first occurrence of each.
+func (entity *Entity) Distinct(key string) []interface{} {
+	var s []interface{}
+	seen := make(map[string]bool)
+	for _, v := range entity.GetSlice(key) {
+		k := distinctKey(v)
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		s = append(s, v)
+	}
+	return s
+}
+
+// DistinctBy returns the elements of the object array at key with duplicate
+// values of field removed, keeping the first occurrence of each.
+// Elements missing field are always kept.
+func (entity *Entity) DistinctBy(key, field string) []map[string]interface{} {
+	var s []map[string]interface{}
+	seen := make(map[string]bool)
+	for _, m := range entity.GetStringMapSlice(key) {
+		if v := entity.child(m).Get(field); v != nil {
+			k := distinctKey(v)
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+		}
+		s = append(s, m)
+	}
+	return s
+}
+
+// Project returns the elements of the object array at key keeping only the
+// listed fields. Nested fields such as "address:city" keep their nesting.
+func (entity *Entity) Project(key string, fields ...string) []map[string]interface{} {
+	var s []map[string]interface{}
+	for _, m := range entity.GetStringMapSlice(key) {
+		src := entity.child(m)
+		dst := entity.child(make(map[string]interface{}))
+		for _, field := range fields {
+			if v := src.Get(field); v != nil {
+				dst.Set(field, v)
+			}
+		}
+		s = append(s, dst.data)
+	}
+	return s
+}
+
+// PageInfo describes a page returned by Paginate.
+type PageInfo struct {
+	Page    int `json:"page"`
+	PerPage int `json:"perPage"`
+	Total   int `json:"total"`
+	Pages   int `json:"pages"`
+}
+
+// Paginate returns page (1-based) of the array at key, holding perPage
+// elements under "items", along with the pagination metadata.
+// A page past the end holds no items.
+func (entity *Entity) Paginate(key string, page, perPage int) (*Entity, PageInfo) {
+	s := entity.GetSlice(key)
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = len(s)
+	}
+
+	info := PageInfo{Page: page, PerPage: perPage, Total: len(s)}
+	if perPage > 0 {
+		info.Pages = (len(s) + perPage - 1) / perPage
+	}
+
+	items := make([]interface{}, 0, perPage)
+	if start := (page - 1) * perPage; start < len(s) {
+		end := start + perPage
+		if end > len(s) {
+			end = len(s)
+		}
+		items = append(items, s[start:end]...)
+	}
+
+	return entity.child(map[string]interface{}{"items": items}), info
+}
+
+// FlattenSlice flattens nested arrays in the array at key up to depth levels,
+// e.g. [[1,2],[3]] becomes [1,2,3] with depth 1. A negative depth flattens
+// completely.
+func (entity *Entity) FlattenSlice(key string, depth int) *Entity {
+	entity.update(key, func(val interface{}) (interface{}, error) {
+		s, err := cast.ToSliceE(val)
+		if err != nil {
+			return nil, errNoChange
+		}
+		return flattenSlice(s, depth), nil
+	})
+	return entity
+}
+
+func flattenSlice(s []interface{}, depth int) []interface{} {
+	flat := make([]interface{}, 0, len(s))
+	for _, v := range s {
+		if inner, err := cast.ToSliceE(v); err == nil && depth != 0 {
+			flat = append(flat, flattenSlice(inner, depth-1)...)
+			continue
+		}
+		flat = append(flat, v)
+	}
+	return flat
+}
+
+// MapSlice returns a new array holding the result of fn for each element of
+// the object array at key. Elements for which fn returns nil are dropped.
+func (entity *Entity) MapSlice(key string, fn func(el *Entity) *Entity) []map[string]interface{} {
+	var s []map[string]interface{}
+	for _, m := range entity.GetStringMapSlice(key) {
+		if el := fn(entity.child(m)); el != nil {
+			s = append(s, el.GetData())
+		}
+	}
+	return s
+}
+
+// ReduceSlice folds the object array at key into a single value, calling fn
+// with the accumulator, starting at init, and each element in turn.
+func (entity *Entity) ReduceSlice(key string, init interface{}, fn func(acc interface{}, el *Entity) interface{}) interface{} {
+	acc := init
+	for _, m := range entity.GetStringMapSlice(key) {
+		acc = fn(acc, entity.child(m))
+	}
+	return acc
+}
+
+// SetAt overwrites the element at index of the array at key. A map value is
+// copied the same way Set copies it.
+// It returns an error if key does not hold an array or index is out of range.
+func (entity *Entity) SetAt(key string, index int, value interface{}) error {
+	return entity.update(key, func(val interface{}) (interface{}, error) {
+		s, err := cast.ToSliceE(val)
+		if err != nil {
+			return nil, fmt.Errorf("value of key %q is not an array", key)
+		}
+		if index < 0 || index >= len(s) {
+			return nil, fmt.Errorf("index %d out of range for key %q with length %d", index, key, len(s))
+		}
+
+		updated := make([]interface{}, len(s))
+		copy(updated, s)
+		updated[index] = entity.copyValue(value)
+		return updated, nil
+	})
+}
+
+// ReverseSlice reverses the order of the array at key.
+func (entity *Entity) ReverseSlice(key string) *Entity {
+	entity.update(key, func(val interface{}) (interface{}, error) {
+		s, err := cast.ToSliceE(val)
+		if err != nil {
+			return nil, errNoChange
+		}
+
+		reversed := make([]interface{}, len(s))
+		for i, v := range s {
+			reversed[len(s)-1-i] = v
+		}
+		return reversed, nil
+	})
+	return entity
+}
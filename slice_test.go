@@ -0,0 +1,227 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"testing"
+)
+
+func ids(e *Entity, key string) []int {
+	var s []int
+	for _, m := range e.GetStringMapSlice(key) {
+		s = append(s, int(m["id"].(float64)))
+	}
+	return s
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestEntity_SortSlice(t *testing.T) {
+	e := NewByJSON([]byte(`{"users": [
+		{"id": 1, "age": 30, "name": "bob", "joined": "2020-03-01T02:00:00+08:00"},
+		{"id": 2, "age": 9, "name": "amy", "joined": "2019-12-01T00:00:00Z"},
+		{"id": 3, "name": "cid", "joined": "2020-02-29T20:00:00Z"},
+		{"id": 4, "age": 100, "name": "dan", "joined": "2020-02-29T19:00:00-05:00"}
+	]}`))
+
+	if got := ids(e.SortSlice("users", "age", false), "users"); !equalInts(got, []int{2, 1, 4, 3}) {
+		t.Errorf("SortSlice by age is %v", got)
+	}
+	if got := ids(e.SortSlice("users", "age", true), "users"); !equalInts(got, []int{4, 1, 2, 3}) {
+		t.Errorf("SortSlice by age desc is %v", got)
+	}
+	if got := ids(e.SortSlice("users", "name", false), "users"); !equalInts(got, []int{2, 1, 3, 4}) {
+		t.Errorf("SortSlice by name is %v", got)
+	}
+	// lexically 2, 4, 3, 1; by instant 2, 1, 3, 4
+	if got := ids(e.SortSlice("users", "joined", false), "users"); !equalInts(got, []int{2, 1, 3, 4}) {
+		t.Errorf("SortSlice by joined is %v", got)
+	}
+}
+
+func TestEntity_GroupBy(t *testing.T) {
+	e := NewByJSON(ordersJSON)
+
+	groups := e.GroupBy("orders", "status")
+	if len(groups) != 2 || len(groups["open"]) != 2 || len(groups["closed"]) != 1 {
+		t.Fatalf("GroupBy status is %v", groups)
+	}
+	if groups["open"][1].GetInt("id") != 3 {
+		t.Error("GroupBy should keep array order within a group")
+	}
+}
+
+func TestEntity_JoinSlices(t *testing.T) {
+	e := NewByJSON([]byte(`{
+		"orders": [{"id": 1, "userId": 10}, {"id": 2, "userId": 11}, {"id": 3, "userId": 10}],
+		"users": [{"id": 10, "name": "amy"}, {"id": 12, "name": "bob"}]
+	}`))
+
+	inner := e.JoinSlices("orders", "users", "userId", "id", InnerJoin)
+	if len(inner) != 2 || inner[0]["name"] != "amy" || inner[1]["id"] != float64(3) {
+		t.Errorf("JoinSlices inner is %v", inner)
+	}
+
+	left := e.JoinSlices("orders", "users", "userId", "id", LeftJoin)
+	if len(left) != 3 || left[1]["name"] != nil || left[1]["id"] != float64(2) {
+		t.Errorf("JoinSlices left is %v", left)
+	}
+}
+
+func TestEntity_Distinct(t *testing.T) {
+	e := NewByJSON([]byte(`{
+		"tags": ["a", "b", "a", 1, "1", 1],
+		"users": [
+			{"id": 1, "email": "a@x.io"},
+			{"id": 2, "email": "b@x.io"},
+			{"id": 3, "email": "a@x.io"},
+			{"id": 4}
+		]
+	}`))
+
+	if tags := e.Distinct("tags"); len(tags) != 4 {
+		t.Errorf("Distinct tags is %v", tags)
+	}
+	e.Set("users", e.DistinctBy("users", "email"))
+	if got := ids(e, "users"); !equalInts(got, []int{1, 2, 4}) {
+		t.Errorf("DistinctBy email is %v", got)
+	}
+}
+
+func TestEntity_Project(t *testing.T) {
+	e := NewByJSON([]byte(`{"users": [
+		{"id": 1, "name": "amy", "password": "x", "address": {"city": "Rome", "zip": "00100"}},
+		{"id": 2, "name": "bob", "password": "y"}
+	]}`))
+
+	s := e.Project("users", "id", "name", "address:city")
+	if len(s) != 2 || len(s[0]) != 3 || len(s[1]) != 2 {
+		t.Fatalf("Project is %v", s)
+	}
+	if _, ok := s[0]["password"]; ok {
+		t.Error("Project should drop unlisted fields")
+	}
+	if New(s[0]).GetString("address:city") != "Rome" || New(s[0]).Get("address:zip") != nil {
+		t.Errorf("Project nested field is %v", s[0]["address"])
+	}
+}
+
+func TestEntity_Paginate(t *testing.T) {
+	e := New(map[string]interface{}{"list": []interface{}{1, 2, 3, 4, 5}})
+
+	p, info := e.Paginate("list", 2, 2)
+	if got := p.GetIntSlice("items"); !equalInts(got, []int{3, 4}) {
+		t.Errorf("Paginate page 2 items is %v", got)
+	}
+	if info != (PageInfo{Page: 2, PerPage: 2, Total: 5, Pages: 3}) {
+		t.Errorf("Paginate page 2 info is %+v", info)
+	}
+
+	p, _ = e.Paginate("list", 3, 2)
+	if got := p.GetIntSlice("items"); !equalInts(got, []int{5}) {
+		t.Errorf("Paginate last page items is %v", got)
+	}
+
+	p, _ = e.Paginate("list", 4, 2)
+	if got := p.GetSlice("items"); len(got) != 0 {
+		t.Errorf("Paginate past the end items is %v", got)
+	}
+}
+
+func TestEntity_FlattenSlice(t *testing.T) {
+	e := NewByJSON([]byte(`{"a": [[1, 2], [3, [4, [5]]], 6], "b": [[1, [2, [3]]]]}`))
+
+	if got := e.FlattenSlice("a", 1).GetSlice("a"); len(got) != 5 {
+		t.Errorf("FlattenSlice depth 1 is %v", got)
+	}
+	if got := e.FlattenSlice("b", -1).GetIntSlice("b"); !equalInts(got, []int{1, 2, 3}) {
+		t.Errorf("FlattenSlice full is %v", got)
+	}
+}
+
+func TestEntity_MapSlice(t *testing.T) {
+	e := NewByJSON(ordersJSON)
+
+	s := e.MapSlice("orders", func(el *Entity) *Entity {
+		if el.GetString("status") != "open" {
+			return nil
+		}
+		return New(nil).Set("ref", el.GetInt("id")*100).Set("amount:total", el.Get("total"))
+	})
+	if len(s) != 2 || s[1]["ref"] != 300 || New(s[0]).GetInt("amount:total") != 120 {
+		t.Errorf("MapSlice is %v", s)
+	}
+}
+
+func TestEntity_ReduceSlice(t *testing.T) {
+	e := NewByJSON([]byte(`{"items": [{"price": 2, "qty": 3}, {"price": 1.5, "qty": 2}]}`))
+
+	total := e.ReduceSlice("items", 0.0, func(acc interface{}, el *Entity) interface{} {
+		return acc.(float64) + el.GetFloat64("price")*el.GetFloat64("qty")
+	})
+	if total != 9.0 {
+		t.Errorf("ReduceSlice weighted total is %v, not 9", total)
+	}
+}
+
+func TestEntity_SetAt(t *testing.T) {
+	e := New(map[string]interface{}{"list": []interface{}{1, 2, 3}, "name": "jack"})
+
+	if err := e.SetAt("list", 1, 20); err != nil {
+		t.Fatal("SetAt in range error:", err)
+	}
+	if got := e.GetIntSlice("list"); !equalInts(got, []int{1, 20, 3}) {
+		t.Errorf("SetAt result is %v", got)
+	}
+	if err := e.SetAt("list", 3, 4); err == nil {
+		t.Error("SetAt out of range should fail")
+	}
+	if err := e.SetAt("name", 0, "x"); err == nil {
+		t.Error("SetAt on a non array should fail")
+	}
+}
+
+func TestEntity_SetAtCopy(t *testing.T) {
+	m := map[string]interface{}{"Port": 1}
+
+	e := New(map[string]interface{}{"list": []interface{}{nil}})
+	e.SetAt("list", 0, m)
+	m["Port"] = 2
+	if e.GetInt("list:0:Port") != 1 {
+		t.Error("SetAt should copy a map value")
+	}
+
+	shared := New(map[string]interface{}{"list": []interface{}{nil}}, WithCaseSensitive())
+	shared.SetAt("list", 0, m)
+	m["Port"] = 3
+	if shared.GetInt("list:0:Port") != 3 {
+		t.Error("SetAt should store a map value as is WithCaseSensitive")
+	}
+
+	lower := New(map[string]interface{}{"list": []interface{}{nil}}, WithCaseInsensitive())
+	lower.SetAt("list", 0, m)
+	if lower.GetInt("list:0:port") != 3 {
+		t.Error("SetAt should normalize the keys of a map value")
+	}
+}
+
+func TestEntity_ReverseSlice(t *testing.T) {
+	e := New(map[string]interface{}{"events": []interface{}{1, 2, 3}})
+
+	if got := e.ReverseSlice("events").GetIntSlice("events"); !equalInts(got, []int{3, 2, 1}) {
+		t.Errorf("ReverseSlice is %v", got)
+	}
+}
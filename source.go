@@ -0,0 +1,69 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"strings"
+)
+
+// The layers reported by Source, in the order Get consults them.
+const (
+	SourceEnv      = "env"
+	SourceData     = "data"
+	SourceFallback = "fallback"
+	SourceDefault  = "default"
+)
+
+// Source returns the layer supplying the value Get returns for the key:
+// SourceEnv for a bound environment variable, SourceData for the data of the
+// Entity, SourceFallback for its fallback chain or SourceDefault for its
+// defaults. It returns "" if no layer has a value for the key.
+func (entity *Entity) Source(key string) string {
+	if layers := entity.sources(key); len(layers) > 0 {
+		return layers[0]
+	}
+	return ""
+}
+
+// IsOverridden reports whether the value Get returns for the key hides a
+// value of a lower layer, e.g. a stored value hiding a default.
+func (entity *Entity) IsOverridden(key string) bool {
+	return len(entity.sources(key)) > 1
+}
+
+// sources returns the layers having a value for the key, highest first.
+func (entity *Entity) sources(key string) []string {
+	entity.evictExpired()
+	entity.mu.RLock()
+	key = entity.normalizeKey(key)
+	path := strings.Split(key, entity.keyDelim)
+
+	var layers []string
+	if _, ok := entity.getEnv(key); ok {
+		layers = append(layers, SourceEnv)
+	}
+	if entity.searchMap(entity.data, path) != nil {
+		layers = append(layers, SourceData)
+	}
+	shadowed := len(path) > 1 && entity.isPathShadowedInDeepMap(path, entity.data) != ""
+	fallback := entity.fallback
+	var def interface{}
+	if entity.defaults != nil {
+		def = entity.searchMap(entity.defaults, path)
+	}
+	entity.mu.RUnlock()
+
+	if shadowed {
+		return layers
+	}
+	if fallback != nil && fallback.resolvePath(path) != nil {
+		layers = append(layers, SourceFallback)
+	}
+	if def != nil {
+		layers = append(layers, SourceDefault)
+	}
+	return layers
+}
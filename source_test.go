@@ -0,0 +1,44 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"os"
+	"testing"
+)
+
+func TestEntity_Source(t *testing.T) {
+	base := New(map[string]interface{}{"db": map[string]interface{}{"host": "base", "port": 5432}})
+	e := New(map[string]interface{}{"db": map[string]interface{}{"host": "local"}, "name": "app", "level": "x"})
+	if err := e.SetFallback(base); err != nil {
+		t.Fatal(err)
+	}
+	e.SetDefault("db:port", 1).SetDefault("timeout", 30).SetDefault("level:debug", true)
+	e.BindEnv("name", "ENTITY_TEST_NAME")
+	os.Setenv("ENTITY_TEST_NAME", "env")
+	defer os.Unsetenv("ENTITY_TEST_NAME")
+
+	for _, tt := range []struct {
+		key        string
+		source     string
+		overridden bool
+	}{
+		{"name", SourceEnv, true},
+		{"db:host", SourceData, true},
+		{"db:port", SourceFallback, true},
+		{"timeout", SourceDefault, false},
+		{"level", SourceData, true},
+		{"level:debug", "", false},
+		{"missing", "", false},
+	} {
+		if got := e.Source(tt.key); got != tt.source {
+			t.Errorf("Source(%q) is %q, not %q", tt.key, got, tt.source)
+		}
+		if got := e.IsOverridden(tt.key); got != tt.overridden {
+			t.Errorf("IsOverridden(%q) is %v, not %v", tt.key, got, tt.overridden)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"github.com/spf13/cast"
+)
+
+// Sub returns an Entity rooted at the object at key, sharing the settings of
+// the Entity, or nil if key is missing or not an object.
+// The returned Entity shares its data with the Entity, unless the object is
+// merged with the fallback chain or defaults as described by SetFallback;
+// use Clone first for an independent copy.
+func (entity *Entity) Sub(key string) *Entity {
+	switch v := entity.Get(key).(type) {
+	case map[string]interface{}:
+		return entity.child(v)
+	case map[interface{}]interface{}:
+		return entity.child(cast.ToStringMap(v))
+	default:
+		return nil
+	}
+}
+
+// GetEntity returns an Entity rooted at the object at key like Sub, but
+// never nil: if key is missing or not an object, it returns an empty Entity
+// sharing the settings of the Entity, so chained reads such as
+// e.GetEntity("a").GetEntity("b").GetString("c") need no nil checks.
+func (entity *Entity) GetEntity(key string) *Entity {
+	if sub := entity.Sub(key); sub != nil {
+		return sub
+	}
+	return entity.child(map[string]interface{}{})
+}
+
+// SubSlice returns an Entity for each element of the object array at key,
+// sharing the settings of the Entity, or nil if key is not an array.
+func (entity *Entity) SubSlice(key string) []*Entity {
+	var s []*Entity
+	for _, m := range entity.GetStringMapSlice(key) {
+		s = append(s, entity.child(m))
+	}
+	return s
+}
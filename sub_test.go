@@ -0,0 +1,65 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func TestEntity_Sub(t *testing.T) {
+	e := New(map[string]interface{}{
+		"event": map[string]interface{}{"header": map[string]interface{}{"name": "TextInput"}},
+		"count": 1,
+	}, WithKeyDelim("."))
+
+	header := e.Sub("event.header")
+	if header == nil || header.GetString("name") != "TextInput" {
+		t.Fatal("Sub 'event.header' should hold name")
+	}
+	if e.Sub("event").GetString("header.name") != "TextInput" {
+		t.Error("Sub should inherit the key delimiter")
+	}
+	if e.Sub("missing") != nil || e.Sub("count") != nil {
+		t.Error("Sub of a missing key or a scalar should be nil")
+	}
+}
+
+func TestEntity_GetEntity(t *testing.T) {
+	e := New(map[string]interface{}{
+		"a":     map[string]interface{}{"b": map[string]interface{}{"c": "deep"}},
+		"count": 1,
+	}, WithCaseInsensitive())
+
+	if got := e.GetEntity("A").GetEntity("b").GetString("C"); got != "deep" {
+		t.Errorf("GetEntity chain is %q, not deep", got)
+	}
+	if got := e.GetEntity("missing").GetEntity("b").GetString("c"); got != "" {
+		t.Errorf("GetEntity chain through a missing key is %q", got)
+	}
+	if sub := e.GetEntity("count"); sub == nil || len(sub.Keys()) != 0 {
+		t.Error("GetEntity of a scalar should be an empty Entity")
+	}
+}
+
+func TestEntity_SubSlice(t *testing.T) {
+	f, err := ioutil.ReadFile("test_data.json")
+	if err != nil {
+		t.Fatal("read fail", err)
+	}
+	e := NewByJSON(f)
+
+	contexts := e.SubSlice("clientContext")
+	if len(contexts) != 2 {
+		t.Fatalf("SubSlice 'clientContext' length is %d", len(contexts))
+	}
+	if contexts[1].GetInt("payload:offsetInMilliseconds") != 1023785 {
+		t.Error("SubSlice elements should be readable by path")
+	}
+	if e.SubSlice("event") != nil {
+		t.Error("SubSlice of an object should be nil")
+	}
+}
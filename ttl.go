@@ -0,0 +1,94 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"log"
+	"strings"
+	"time"
+)
+
+// SetWithTTL sets the value for the key like Set, but the key expires after
+// ttl and then behaves as missing. Expired keys are removed lazily on the next
+// read, or periodically by a janitor started with StartJanitor.
+func (entity *Entity) SetWithTTL(key string, value interface{}, ttl time.Duration) *Entity {
+	entity.mu.Lock()
+	defer entity.unlock()
+
+	entity.clearTTL(key)
+	if err := entity.set(key, value); err != nil {
+		log.Println(err)
+		return entity
+	}
+	if entity.expires == nil {
+		entity.expires = make(map[string]time.Time)
+	}
+	entity.expires[entity.normalizeKey(key)] = time.Now().Add(ttl)
+	return entity
+}
+
+// StartJanitor removes expired keys every interval until stop is called.
+func (entity *Entity) StartJanitor(interval time.Duration) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				entity.evictExpired()
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// clearTTL forgets the expiry of key and of the keys nested under it,
+// as a new value is about to replace them.
+func (entity *Entity) clearTTL(key string) {
+	if len(entity.expires) == 0 {
+		return
+	}
+	key = entity.normalizeKey(key)
+	prefix := key + entity.keyDelim
+	for k := range entity.expires {
+		if k == key || strings.HasPrefix(k, prefix) {
+			delete(entity.expires, k)
+		}
+	}
+}
+
+// evictExpired removes the keys whose TTL has passed.
+func (entity *Entity) evictExpired() {
+	entity.mu.RLock()
+	if len(entity.expires) == 0 {
+		entity.mu.RUnlock()
+		return
+	}
+	now := time.Now()
+	expired := false
+	for _, t := range entity.expires {
+		if !now.Before(t) {
+			expired = true
+			break
+		}
+	}
+	entity.mu.RUnlock()
+	if !expired {
+		return
+	}
+
+	entity.mu.Lock()
+	defer entity.unlock()
+	for key, t := range entity.expires {
+		if !now.Before(t) {
+			entity.remove(key, false)
+			delete(entity.expires, key)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEntity_SetWithTTL(t *testing.T) {
+	e := New(nil)
+	e.SetWithTTL("session:token", "abc", 20*time.Millisecond)
+	e.SetWithTTL("session:user", "jack", time.Hour)
+
+	if e.GetString("session:token") != "abc" {
+		t.Fatal("GetString 'session:token' should be set before expiry")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if e.Get("session:token") != nil {
+		t.Error("Get 'session:token' should be missing after expiry")
+	}
+	if _, ok := e.GetStringMap("session")["token"]; ok {
+		t.Error("expired key should be removed from its parent")
+	}
+	if e.GetString("session:user") != "jack" {
+		t.Error("GetString 'session:user' should not expire yet")
+	}
+
+	e.SetWithTTL("name", "jack", 20*time.Millisecond)
+	e.Set("name", "rose")
+	time.Sleep(30 * time.Millisecond)
+	if e.GetString("name") != "rose" {
+		t.Error("Set should clear the TTL of the key")
+	}
+}
+
+func TestEntity_StartJanitor(t *testing.T) {
+	e := New(nil)
+	e.SetWithTTL("name", "jack", 10*time.Millisecond)
+
+	stop := e.StartJanitor(5 * time.Millisecond)
+	defer stop()
+
+	time.Sleep(40 * time.Millisecond)
+	e.mu.RLock()
+	_, ok := e.data["name"]
+	e.mu.RUnlock()
+	if ok {
+		t.Error("janitor should remove the expired key")
+	}
+}
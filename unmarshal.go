@@ -0,0 +1,61 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"github.com/mitchellh/mapstructure"
+)
+
+// DecoderConfigOption can be passed to Unmarshal and UnmarshalKey to
+// configure the mapstructure.DecoderConfig used to decode.
+type DecoderConfigOption func(*mapstructure.DecoderConfig)
+
+// defaultDecoderConfig returns a mapstructure.DecoderConfig decoding weakly
+// typed input into output, with hooks for durations and comma separated
+// slices.
+func defaultDecoderConfig(output interface{}, opts ...DecoderConfigOption) *mapstructure.DecoderConfig {
+	c := &mapstructure.DecoderConfig{
+		Result:           output,
+		WeaklyTypedInput: true,
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			stringToDurationHook,
+			mapstructure.StringToSliceHookFunc(","),
+		),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// decode decodes input into output using config.
+func decode(input interface{}, config *mapstructure.DecoderConfig) error {
+	decoder, err := mapstructure.NewDecoder(config)
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(input)
+}
+
+// Unmarshal decodes the whole Entity into a struct or map pointed to by
+// rawVal, matching fields by their `mapstructure` tag or, case-insensitively,
+// by name.
+func (entity *Entity) Unmarshal(rawVal interface{}, opts ...DecoderConfigOption) error {
+	data, done := entity.view()
+	defer done()
+
+	var input interface{} = data
+	if entity.expandEnv {
+		input = expandValue(data)
+	}
+	return decode(input, defaultDecoderConfig(rawVal, opts...))
+}
+
+// UnmarshalKey decodes the value for the key into a struct, map, slice or
+// scalar pointed to by rawVal, like Unmarshal does for the whole Entity.
+func (entity *Entity) UnmarshalKey(key string, rawVal interface{}, opts ...DecoderConfigOption) error {
+	return decode(entity.Get(key), defaultDecoderConfig(rawVal, opts...))
+}
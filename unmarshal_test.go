@@ -0,0 +1,75 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+type header struct {
+	Namespace string
+	Name      string
+	MessageID string `mapstructure:"messageId"`
+}
+
+type payload struct {
+	Event struct {
+		Header    header
+		Simulator bool
+	}
+	ClientContext []struct {
+		Header  header
+		Payload map[string]interface{}
+	} `mapstructure:"clientContext"`
+}
+
+func TestEntity_Unmarshal(t *testing.T) {
+	f, err := ioutil.ReadFile("test_data.json")
+	if err != nil {
+		t.Fatal("read fail", err)
+	}
+	e := NewByJSON(f)
+
+	var p payload
+	if err := e.Unmarshal(&p); err != nil {
+		t.Fatal("Unmarshal error:", err)
+	}
+	if !p.Event.Simulator || p.Event.Header.MessageID != "b80537ac-4f48-4852-9869-41c550c704ab" {
+		t.Errorf("Unmarshal event is %+v", p.Event)
+	}
+	if len(p.ClientContext) != 2 || p.ClientContext[1].Header.Name != "SpeechState" {
+		t.Errorf("Unmarshal clientContext is %+v", p.ClientContext)
+	}
+}
+
+func TestEntity_UnmarshalKey(t *testing.T) {
+	e := NewByJSON([]byte(`{"db": {"host": "localhost", "port": "5432", "timeout": "3s", "tags": "a,b"}}`))
+
+	var db struct {
+		Host    string
+		Port    int
+		Timeout time.Duration
+		Tags    []string
+	}
+	if err := e.UnmarshalKey("db", &db); err != nil {
+		t.Fatal("UnmarshalKey error:", err)
+	}
+	if db.Host != "localhost" || db.Port != 5432 || db.Timeout != 3*time.Second || len(db.Tags) != 2 {
+		t.Errorf("UnmarshalKey db is %+v", db)
+	}
+
+	var strict struct{ Port int }
+	err := e.UnmarshalKey("db", &strict, func(c *mapstructure.DecoderConfig) {
+		c.WeaklyTypedInput = false
+	})
+	if err == nil {
+		t.Error("UnmarshalKey with strict typing should fail on a string port")
+	}
+}
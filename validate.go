@@ -0,0 +1,221 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+
+	"github.com/spf13/cast"
+)
+
+// Kind is the expected type of a value checked with RuleKind.
+type Kind string
+
+// Kinds of values.
+const (
+	KindString Kind = "string"
+	KindInt    Kind = "int"
+	KindFloat  Kind = "float"
+	KindBool   Kind = "bool"
+	KindMap    Kind = "map"
+	KindSlice  Kind = "slice"
+)
+
+// Rule checks the value at a key path, returning an error describing why the
+// value is invalid.
+type Rule func(v interface{}) error
+
+// ValidationError is returned by Validate for each invalid key path.
+type ValidationError struct {
+	Key string
+	Err error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Key + ": " + e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// errRequired is the error of a required key that is missing.
+var errRequired = errors.New("is required")
+
+// Schema declares the required key paths of an Entity and the rules their
+// values must follow.
+type Schema struct {
+	required []string
+	fields   []schemaField
+}
+
+type schemaField struct {
+	key   string
+	rules []Rule
+}
+
+// NewSchema returns an empty Schema.
+func NewSchema() *Schema {
+	return new(Schema)
+}
+
+// Require declares keys that must be present.
+func (s *Schema) Require(keys ...string) *Schema {
+	s.required = append(s.required, keys...)
+	return s
+}
+
+// Field declares rules for the value at key. The rules are only checked when
+// key is present.
+func (s *Schema) Field(key string, rules ...Rule) *Schema {
+	s.fields = append(s.fields, schemaField{key: key, rules: rules})
+	return s
+}
+
+// Validate checks the Entity against schema, returning a *ValidationError for
+// each missing required key and each value breaking a rule, in the order
+// they were declared. Only the first broken rule of a key is reported.
+func (entity *Entity) Validate(schema *Schema) []error {
+	var errs []error
+	for _, key := range schema.required {
+		if !entity.Has(key) {
+			errs = append(errs, &ValidationError{Key: key, Err: errRequired})
+		}
+	}
+	for _, f := range schema.fields {
+		if !entity.Has(f.key) {
+			continue
+		}
+		v := entity.Get(f.key)
+		for _, rule := range f.rules {
+			if err := rule(v); err != nil {
+				errs = append(errs, &ValidationError{Key: f.key, Err: err})
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// RuleKind returns a Rule requiring values of kind. KindInt accepts floats
+// without a fractional part, as JSON decodes all numbers as float64.
+func RuleKind(kind Kind) Rule {
+	return func(v interface{}) error {
+		if kindOf(v) == kind {
+			return nil
+		}
+		if kind == KindFloat && kindOf(v) == KindInt {
+			return nil
+		}
+		return fmt.Errorf("must be %s, not %T", kind, v)
+	}
+}
+
+// kindOf returns the Kind of v, or "" if it has none.
+func kindOf(v interface{}) Kind {
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.String:
+		return KindString
+	case reflect.Bool:
+		return KindBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return KindInt
+	case reflect.Float32, reflect.Float64:
+		if f := cast.ToFloat64(v); f == math.Trunc(f) && !math.IsInf(f, 0) {
+			return KindInt
+		}
+		return KindFloat
+	case reflect.Map:
+		return KindMap
+	case reflect.Slice, reflect.Array:
+		return KindSlice
+	default:
+		return ""
+	}
+}
+
+// RuleMin returns a Rule requiring numbers to be at least n, and strings,
+// arrays and objects to have at least n elements.
+func RuleMin(n float64) Rule {
+	return func(v interface{}) error {
+		size, err := sizeOf(v)
+		if err != nil {
+			return err
+		}
+		if size < n {
+			return fmt.Errorf("must be at least %v, not %v", n, size)
+		}
+		return nil
+	}
+}
+
+// RuleMax returns a Rule requiring numbers to be at most n, and strings,
+// arrays and objects to have at most n elements.
+func RuleMax(n float64) Rule {
+	return func(v interface{}) error {
+		size, err := sizeOf(v)
+		if err != nil {
+			return err
+		}
+		if size > n {
+			return fmt.Errorf("must be at most %v, not %v", n, size)
+		}
+		return nil
+	}
+}
+
+// sizeOf returns the value of a number or the length of v.
+func sizeOf(v interface{}) (float64, error) {
+	if isNumber(v) {
+		return cast.ToFloat64(v), nil
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.String:
+		return float64(len([]rune(rv.String()))), nil
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return float64(rv.Len()), nil
+	default:
+		return 0, fmt.Errorf("has no size: %T", v)
+	}
+}
+
+// RuleMatch returns a Rule requiring strings matching the regular expression
+// pattern. It panics if pattern does not compile.
+func RuleMatch(pattern string) Rule {
+	re := regexp.MustCompile(pattern)
+	return func(v interface{}) error {
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("must be string, not %T", v)
+		}
+		if !re.MatchString(s) {
+			return fmt.Errorf("%q does not match %s", s, pattern)
+		}
+		return nil
+	}
+}
+
+// RuleOneOf returns a Rule requiring values equal to one of values. Numbers
+// are compared by value regardless of their type.
+func RuleOneOf(values ...interface{}) Rule {
+	return func(v interface{}) error {
+		for _, value := range values {
+			if isNumber(v) && isNumber(value) {
+				if c, _ := compare(v, value); c == 0 {
+					return nil
+				}
+			} else if reflect.DeepEqual(v, value) {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %v, not %v", values, v)
+	}
+}
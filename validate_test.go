@@ -0,0 +1,74 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEntity_Validate(t *testing.T) {
+	schema := NewSchema().
+		Require("user:id", "user:email", "event").
+		Field("user:id", RuleKind(KindInt), RuleMin(1)).
+		Field("user:email", RuleKind(KindString), RuleMatch(`^[^@]+@[^@]+$`)).
+		Field("user:name", RuleKind(KindString), RuleMax(5)).
+		Field("user:tags", RuleKind(KindSlice), RuleMax(2)).
+		Field("status", RuleOneOf("active", "disabled"))
+
+	valid := NewByJSON([]byte(`{"user": {"id": 7, "email": "jack@example.com", "tags": ["a"]}, "event": {}, "status": "active"}`))
+	if errs := valid.Validate(schema); len(errs) != 0 {
+		t.Errorf("Validate of a valid entity errors are %v", errs)
+	}
+
+	invalid := NewByJSON([]byte(`{"user": {"id": 1.5, "email": "jack", "name": "Jackson", "tags": ["a", "b", "c"]}, "status": "gone"}`))
+	want := []string{
+		"event: is required",
+		"user:id: must be int, not float64",
+		`user:email: "jack" does not match ^[^@]+@[^@]+$`,
+		"user:name: must be at most 5, not 7",
+		"user:tags: must be at most 2, not 3",
+		"status: must be one of [active disabled], not gone",
+	}
+	errs := invalid.Validate(schema)
+	if len(errs) != len(want) {
+		t.Fatalf("Validate errors are %v", errs)
+	}
+	for i, err := range errs {
+		if err.Error() != want[i] {
+			t.Errorf("Validate error %d is %q, not %q", i, err, want[i])
+		}
+	}
+
+	var verr *ValidationError
+	if !errors.As(errs[0], &verr) || verr.Key != "event" {
+		t.Error("Validate errors should be *ValidationError")
+	}
+}
+
+func TestRules(t *testing.T) {
+	for _, tt := range []struct {
+		rule  Rule
+		value interface{}
+		ok    bool
+	}{
+		{RuleKind(KindInt), 3, true},
+		{RuleKind(KindInt), float64(3), true},
+		{RuleKind(KindFloat), 3, true},
+		{RuleKind(KindBool), "true", false},
+		{RuleKind(KindMap), map[string]interface{}{}, true},
+		{RuleMin(2), "é", false},
+		{RuleMin(2), []interface{}{1, 2}, true},
+		{RuleMin(0), true, false},
+		{RuleMatch(`^\d+$`), 12, false},
+		{RuleOneOf(1, 2), float64(2), true},
+		{RuleOneOf("1"), 1, false},
+	} {
+		if err := tt.rule(tt.value); (err == nil) != tt.ok {
+			t.Errorf("rule on %#v error is %v", tt.value, err)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"errors"
+)
+
+// ErrVersionConflict is returned by SetIfVersion when the Entity was
+// modified since the expected version was read.
+var ErrVersionConflict = errors.New("version conflict")
+
+// Version returns the version of the Entity, increased by every modification.
+func (entity *Entity) Version() uint64 {
+	entity.mu.RLock()
+	defer entity.mu.RUnlock()
+
+	return entity.version
+}
+
+// SetIfVersion sets the value for the key only if the Entity is still at
+// version, returning ErrVersionConflict otherwise.
+func (entity *Entity) SetIfVersion(version uint64, key string, value interface{}) error {
+	entity.evictExpired()
+	entity.mu.Lock()
+	defer entity.unlock()
+
+	if entity.version != version {
+		return ErrVersionConflict
+	}
+	entity.clearTTL(key)
+	return entity.set(key, value)
+}
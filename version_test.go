@@ -0,0 +1,33 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"testing"
+)
+
+func TestEntity_SetIfVersion(t *testing.T) {
+	e := New(nil)
+	if e.Version() != 0 {
+		t.Errorf("Version of a new entity is %d, not 0", e.Version())
+	}
+
+	e.Set("name", "jack")
+	v := e.Version()
+	if v != 1 {
+		t.Errorf("Version after Set is %d, not 1", v)
+	}
+
+	if err := e.SetIfVersion(v, "name", "rose"); err != nil {
+		t.Fatal("SetIfVersion with current version error:", err)
+	}
+	if err := e.SetIfVersion(v, "name", "lost"); err != ErrVersionConflict {
+		t.Errorf("SetIfVersion with stale version error is %v", err)
+	}
+	if e.GetString("name") != "rose" {
+		t.Error("stale SetIfVersion should not modify the entity")
+	}
+}
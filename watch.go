@@ -0,0 +1,152 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"sync"
+)
+
+// WatchOption configures a watcher registered with Watch.
+type WatchOption func(w *watcher)
+
+// WatchAsync makes the watcher receive changes through a channel buffering
+// up to size changes, calling fn from its own goroutine in the order the
+// changes were made. Changes block once the buffer is full, until fn takes
+// the next change or the watcher is canceled.
+func WatchAsync(size int) WatchOption {
+	return func(w *watcher) {
+		w.ch = make(chan watchEvent, size)
+	}
+}
+
+// watcher is a callback registered with Watch.
+type watcher struct {
+	prefix string
+	fn     func(key string, old, new interface{})
+	ch     chan watchEvent
+
+	// done is closed by cancel; once guards closing it
+	done chan struct{}
+	once sync.Once
+}
+
+// watchEvent is a change queued for a watcher.
+type watchEvent struct {
+	w        *watcher
+	key      string
+	old, new interface{}
+}
+
+// Watch calls fn for each change made by Set, Delete, Merge and the other
+// mutating methods to a key equal to keyPrefix, nested under it or
+// containing it. An empty keyPrefix watches all keys. fn receives the changed
+// key with its value before and after the change, nil if missing.
+// By default fn is called synchronously once the Entity is unlocked, so it
+// may read and modify the Entity. The returned cancel func stops the calls.
+func (entity *Entity) Watch(keyPrefix string, fn func(key string, old, new interface{}), opts ...WatchOption) (cancel func()) {
+	w := &watcher{fn: fn, done: make(chan struct{})}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.ch != nil {
+		go w.run()
+	}
+
+	entity.mu.Lock()
+	if entity.keyDelim == "" {
+		entity.keyDelim = ":"
+	}
+	w.prefix = entity.normalizeKey(keyPrefix)
+	if entity.watchers == nil {
+		entity.watchers = make(map[uint64]*watcher)
+	}
+	id := entity.nextWatcher
+	entity.nextWatcher++
+	entity.watchers[id] = w
+	entity.mu.Unlock()
+
+	return func() {
+		entity.mu.Lock()
+		delete(entity.watchers, id)
+		entity.mu.Unlock()
+
+		w.once.Do(func() { close(w.done) })
+	}
+}
+
+// run calls fn for the changes received by an async watcher until it is
+// canceled.
+func (w *watcher) run() {
+	for {
+		select {
+		case ev := <-w.ch:
+			if w.canceled() {
+				return
+			}
+			w.fn(ev.key, ev.old, ev.new)
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// canceled reports whether the cancel func of the watcher was called.
+func (w *watcher) canceled() bool {
+	select {
+	case <-w.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// watchedValue returns the value at the normalized path if the Entity has
+// watchers, to be passed to notify as the old value.
+func (entity *Entity) watchedValue(path []string) interface{} {
+	if len(entity.watchers) == 0 {
+		return nil
+	}
+	v, _ := entity.lookupPath(path)
+	return v
+}
+
+// notify queues a change of key for the matching watchers, to be delivered
+// by unlock.
+func (entity *Entity) notify(key string, old, new interface{}) {
+	for _, w := range entity.watchers {
+		if w.prefix == "" || isRelatedKey(key, w.prefix, entity.keyDelim) {
+			entity.events = append(entity.events, watchEvent{w: w, key: key, old: old, new: new})
+		}
+	}
+}
+
+// unlock unlocks the Entity, then delivers the queued changes to watchers.
+func (entity *Entity) unlock() {
+	events := entity.events
+	entity.events = nil
+	entity.mu.Unlock()
+
+	for _, ev := range events {
+		ev.w.deliver(ev)
+	}
+}
+
+// deliver passes ev to the watcher unless it was canceled. Sending to an
+// async watcher gives up once it is canceled, so a callback may cancel its
+// own watcher while changes are blocked on the full buffer.
+func (w *watcher) deliver(ev watchEvent) {
+	if w.canceled() {
+		return
+	}
+	if w.ch != nil {
+		select {
+		case w.ch <- ev:
+		case <-w.done:
+		}
+		return
+	}
+	w.fn(ev.key, ev.old, ev.new)
+}
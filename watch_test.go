@@ -0,0 +1,93 @@
+// Copyright © 2020 - present. liyongfei <liyongfei@walktotop.com>.
+//
+// Use of this source code is governed by an MIT-style
+// license that can be found in the LICENSE file.
+
+package entity
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEntity_Watch(t *testing.T) {
+	e := NewByJSON([]byte(`{"db": {"host": "localhost"}, "debug": false}`))
+
+	var got []Change
+	var keys []string
+	cancel := e.Watch("db", func(key string, old, new interface{}) {
+		keys = append(keys, key)
+		got = append(got, Change{Old: old, New: new})
+		// watchers may use the Entity
+		e.GetString("db:host")
+	})
+
+	e.Set("db:host", "db.prod")
+	e.Set("debug", true)
+	e.MergeMap(map[string]interface{}{"db": map[string]interface{}{"port": 5432}})
+	e.Delete("db:host")
+	e.Set("db", nil)
+
+	wantKeys := []string{"db:host", "db:port", "db:host", "db"}
+	want := []Change{
+		{Old: "localhost", New: "db.prod"},
+		{New: 5432},
+		{Old: "db.prod"},
+		{Old: map[string]interface{}{"port": 5432}},
+	}
+	if !reflect.DeepEqual(keys, wantKeys) || !reflect.DeepEqual(got, want) {
+		t.Errorf("Watch calls are %v %v", keys, got)
+	}
+
+	cancel()
+	e.Set("db:host", "localhost")
+	if len(keys) != len(wantKeys) {
+		t.Error("a canceled watcher should not be called")
+	}
+}
+
+func TestEntity_WatchAsync(t *testing.T) {
+	e := New(nil)
+	ch := make(chan string, 2)
+	cancel := e.Watch("", func(key string, old, new interface{}) {
+		ch <- key
+	}, WatchAsync(4))
+	defer cancel()
+
+	e.Set("a", 1).Set("b:c", 2)
+	for _, want := range []string{"a", "b:c"} {
+		select {
+		case key := <-ch:
+			if key != want {
+				t.Errorf("Watch key is %q, not %q", key, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Watch with WatchAsync was not called")
+		}
+	}
+}
+
+func TestEntity_WatchAsyncSelfCancel(t *testing.T) {
+	e := New(nil)
+	calls := make(chan string, 4)
+	var cancel func()
+	cancel = e.Watch("", func(key string, old, new interface{}) {
+		calls <- key
+		cancel()
+	}, WatchAsync(0))
+
+	done := make(chan struct{})
+	go func() {
+		e.MergeMap(map[string]interface{}{"a": 1, "b": 2, "c": 3})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("MergeMap blocked on a watcher that canceled itself")
+	}
+	if len(calls) != 1 {
+		t.Errorf("a self-canceling watcher was called %d times", len(calls))
+	}
+}